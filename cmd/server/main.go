@@ -1,54 +1,172 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
+	"time"
 
 	"detector_plagio/backend/internal/api"
 	"detector_plagio/backend/internal/config"
+	"detector_plagio/backend/internal/ports"
 	"detector_plagio/backend/internal/repo"
 	"detector_plagio/backend/internal/service"
 	"detector_plagio/backend/internal/usecase"
-	"detector_plagio/backend/internal/ports"
 )
 
 func main() {
+	// "server adopt [--dry-run]" runs a maintenance command instead of serving.
+	var command string
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
 	cfg := config.Load()
 	repoFS := repo.NewFSRepo(cfg)
 	userRepo, err := repo.NewFSUserRepo(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
+	uploadRepo, err := repo.NewFSUploadRepo(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	quarantineRepo, err := repo.NewFSQuarantineRepo(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	revisionRepo, err := repo.NewFSTextRevisionRepo(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	var extractors []ports.Extractor = []ports.Extractor{
-		service.NewPDFToTextExtractor(),
-		service.NewDocxSofficeExtractor(),
+	sofficePool, err := service.NewSofficePool(cfg.SofficeWorkers)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer sofficePool.Close()
+	var sofficeDaemon *service.SofficeDaemonPool
+	if cfg.SofficeDaemons > 0 && command == "" {
+		sofficeDaemon, err = service.NewSofficeDaemonPool(cfg.SofficeDaemons, cfg.SofficeDaemonPort)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sofficeDaemon.Start()
+		defer sofficeDaemon.Stop()
 	}
+
+	extractors := service.NewExtractorRegistry(
+		service.NewPDFToTextExtractor(cfg.PDFTimeout),
+		service.NewODTExtractor(),
+		service.NewRTFExtractor(),
+		service.NewHTMLExtractor(),
+		service.NewMarkdownExtractor(),
+		service.NewDocxSofficeExtractor(sofficePool, sofficeDaemon, cfg.SofficeTimeout),
+	)
+	cfg.AllowedExtMap = extractors.AllowedExtMap()
 	normalizer := service.NewNormalizer()
+	sniffer := service.NewContentSniffer()
+	decoder := service.NewCharsetDecoder()
+	obfuscation := service.NewObfuscationDetector()
+	var ocr ports.OCR
+	if cfg.OCRCommand != "" {
+		ocr = service.NewCommandOCR(cfg.OCRCommand, cfg.OCRTimeout)
+	}
+	index, err := service.NewInvertedIndex(cfg.IndexPath())
+	if errors.Is(err, service.ErrIndexLocked) && command != "" {
+		log.Fatalf("%v: stop the server first, or use POST /admin/documents/adopt while it runs", err)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
 	sim := service.NewSimilarity()
 	jwt := service.NewJWT(cfg.JWTSecret)
 
-	ingest := usecase.NewIngest(cfg, repoFS, extractors, normalizer)
+	ingest := usecase.NewIngest(cfg, repoFS, extractors, normalizer, sniffer, decoder, obfuscation, ocr, quarantineRepo, index)
 	compare := usecase.NewCompare(repoFS, normalizer, sim)
 	auth := usecase.NewAuth(userRepo)
 	user := usecase.NewUser(userRepo)
+	uploads := usecase.NewUploads(cfg, uploadRepo, ingest)
+	metadata := usecase.NewMetadata(repoFS)
+	quarantine := usecase.NewQuarantine(quarantineRepo, ingest)
+	revisions := usecase.NewTextRevisions(repoFS, revisionRepo, normalizer, obfuscation, index)
+	adopt := usecase.NewAdopt(repoFS, ingest)
+	reindex := usecase.NewReindexer(cfg, repoFS, ingest, revisionRepo)
+	search := usecase.NewSearch(repoFS, normalizer, index)
+	lookup := usecase.NewLookup(repoFS, normalizer, index)
+	check := usecase.NewCheck(cfg, repoFS, ingest, compare)
+	originality := usecase.NewOriginality(repoFS, lookup)
+	reports := usecase.NewReports(repoFS, compare, lookup, map[string]ports.ReportRenderer{
+		"html": service.NewHTMLReport(),
+		"pdf":  service.NewPDFReport(),
+	})
+	export := usecase.NewExport(repoFS, originality, service.NewDocxAnnotator())
+
+	switch command {
+	case "":
+	case "adopt":
+		code := runAdopt(adopt, os.Args[2:])
+		if err := index.Close(); err != nil {
+			log.Printf("closing index: %v", err)
+			code = 1
+		}
+		sofficePool.Close()
+		os.Exit(code)
+	default:
+		log.Fatalf("unknown command %q", command)
+	}
+
+	go uploads.RunJanitor(context.Background(), 10*time.Minute)
+	go check.RunJanitor(context.Background(), 10*time.Minute)
+	if err := search.Sync(); err != nil {
+		log.Printf("Search: index sync failed: %v", err)
+	}
+	reindex.ResumeInterrupted()
+	if len(cfg.WatchDirs) > 0 {
+		go usecase.NewWatcher(cfg, repoFS, ingest).Run(context.Background())
+	}
 
-	handlers := api.NewHandlers(cfg, repoFS, userRepo, ingest, compare, auth, user, jwt)
+	handlers := api.NewHandlers(cfg, repoFS, userRepo, ingest, compare, auth, user, uploads, metadata, quarantine, revisions, adopt, reindex, search, lookup, check, originality, reports, export, jwt)
 
 	mux := http.NewServeMux()
 
 	// Public routes
 	mux.HandleFunc("POST /login", handlers.Login)
 	mux.HandleFunc("POST /documents/upload", handlers.Upload)
+	mux.HandleFunc("POST /documents/upload-archive", handlers.UploadArchive)
+	mux.HandleFunc("POST /uploads", handlers.CreateUpload)
+	mux.HandleFunc("HEAD /uploads/{id}", handlers.UploadStatus)
+	mux.HandleFunc("PATCH /uploads/{id}", handlers.PatchUpload)
+	mux.HandleFunc("DELETE /uploads/{id}", handlers.CancelUpload)
+	mux.HandleFunc("POST /uploads/{id}/finalize", handlers.FinalizeUpload)
 	mux.HandleFunc("GET /documents/{id}", handlers.GetDoc)
+	mux.HandleFunc("GET /documents/{id}/metadata", handlers.GetDocMetadata)
+	mux.HandleFunc("GET /documents/{id}/originality", handlers.Originality)
+	mux.HandleFunc("GET /documents/{id}/annotated.docx", handlers.AnnotatedDocx)
+	mux.HandleFunc("GET /documents/metadata/collisions", handlers.MetadataCollisions)
+	mux.Handle("PUT /documents/{id}/text", handlers.AuthMiddleware(http.HandlerFunc(handlers.SetDocText)))
+	mux.HandleFunc("GET /documents/{id}/text/revisions", handlers.ListTextRevisions)
+	mux.HandleFunc("GET /documents/{id}/text/revisions/{rev}", handlers.GetTextRevision)
 	mux.HandleFunc("GET /documents/ids", handlers.ListIDs)
 	mux.HandleFunc("GET /documents", handlers.ListDocs)
 	mux.HandleFunc("DELETE /documents/{id}", handlers.DeleteDoc)
 	mux.HandleFunc("GET /folders", handlers.ListFolders)
 	mux.HandleFunc("POST /compare", handlers.Compare)
+	mux.HandleFunc("POST /compare/matrix", handlers.CompareMatrix)
+	mux.HandleFunc("POST /compare/folders", handlers.CompareFolders)
+	mux.HandleFunc("GET /compare/{id1}/{id2}/report", handlers.CompareReport)
 	mux.HandleFunc("GET /similar/{id}", handlers.Similar)
+	mux.HandleFunc("GET /search", handlers.Search)
+	mux.HandleFunc("POST /lookup", handlers.Lookup)
+	mux.HandleFunc("POST /check", handlers.Check)
+	mux.HandleFunc("GET /checks/{id}", handlers.GetCheck)
 
 	// Admin routes
 	adminMux := http.NewServeMux()
@@ -56,11 +174,61 @@ func main() {
 	adminMux.HandleFunc("POST /users", handlers.CreateUser)
 	adminMux.HandleFunc("PUT /users/{id}", handlers.UpdateUser)
 	adminMux.HandleFunc("DELETE /users/{id}", handlers.DeleteUser)
+	adminMux.HandleFunc("GET /quarantine", handlers.ListQuarantine)
+	adminMux.HandleFunc("GET /quarantine/{id}", handlers.GetQuarantine)
+	adminMux.HandleFunc("POST /quarantine/{id}/retry", handlers.RetryQuarantine)
+	adminMux.HandleFunc("POST /quarantine/retry", handlers.RetryAllQuarantine)
+	adminMux.HandleFunc("DELETE /quarantine/{id}", handlers.PurgeQuarantineItem)
+	adminMux.HandleFunc("DELETE /quarantine", handlers.PurgeQuarantine)
+	adminMux.HandleFunc("GET /metrics/extraction", handlers.ExtractionMetrics)
+	adminMux.HandleFunc("POST /documents/adopt", handlers.AdoptOrphans)
+	adminMux.HandleFunc("PUT /documents/{id}/role", handlers.SetDocRole)
+	adminMux.HandleFunc("POST /reindex", handlers.StartReindex)
+	adminMux.HandleFunc("GET /reindex", handlers.ReindexStatus)
+	adminMux.HandleFunc("POST /reindex/stop", handlers.StopReindex)
+	adminMux.HandleFunc("POST /reindex/resume", handlers.ResumeReindex)
+	adminMux.HandleFunc("DELETE /reindex", handlers.AbortReindex)
 	mux.Handle("/admin/", http.StripPrefix("/admin", handlers.AuthMiddleware(adminMux)))
 
 	addr := ":" + strconv.Itoa(cfg.Port)
+	srv := &http.Server{Addr: addr, Handler: withCORS(mux)}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdown); err != nil {
+			log.Printf("shutdown: %v", err)
+		}
+	}()
 	fmt.Println("Server listening on", addr)
-	log.Fatal(http.ListenAndServe(addr, withCORS(mux)))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+	if err := index.Close(); err != nil {
+		log.Printf("closing index: %v", err)
+	}
+}
+
+// runAdopt prints the adopt report as JSON; the exit code is 1 when an
+// orphan could not be adopted.
+func runAdopt(adopt *usecase.Adopt, args []string) int {
+	fs := flag.NewFlagSet("adopt", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "only report the orphans")
+	fs.Parse(args)
+	report, err := adopt.Run(context.Background(), *dryRun)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
 }
 
 func withCORS(next http.Handler) http.Handler {
@@ -72,8 +240,9 @@ func withCORS(next http.Handler) http.Handler {
     }
     w.Header().Set("Access-Control-Allow-Origin", origin)
     w.Header().Set("Vary", "Origin")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-    w.Header().Set("Access-control-allow-methods", "GET,POST,OPTIONS,DELETE,PUT")
+    w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Upload-Length, Upload-Offset, Upload-Metadata, Tus-Resumable")
+    w.Header().Set("Access-control-allow-methods", "GET,POST,OPTIONS,DELETE,PUT,PATCH,HEAD")
+    w.Header().Set("Access-Control-Expose-Headers", "Location, Upload-Offset, Upload-Length, Upload-Expires, Tus-Resumable")
     // some browsers expect 204 on preflight
     if r.Method == http.MethodOptions {
       w.WriteHeader(http.StatusNoContent)
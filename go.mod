@@ -2,9 +2,8 @@ module detector_plagio/backend
 
 go 1.25
 
-require github.com/google/uuid v1.6.0
-
 require (
-	github.com/golang-jwt/jwt/v5 v5.3.0 // indirect
-	golang.org/x/crypto v0.42.0 // indirect
+	github.com/golang-jwt/jwt/v5 v5.3.0
+	github.com/google/uuid v1.6.0
+	golang.org/x/crypto v0.42.0
 )
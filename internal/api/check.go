@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"detector_plagio/backend/internal/usecase"
+)
+
+// Check ranks the "file" part against the corpus without storing it.
+// Optional form values: repeated "folder" to restrict the comparison and
+// "topK" (default 10, at most 100); ?roles= selects the corpus roles.
+func (h *Handlers) Check(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(h.cfg.MaxUploadMB << 20); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+	f, fh, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file missing", 400)
+		return
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	roles, err := roleFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	topK := 10
+	if v := r.FormValue("topK"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 100 {
+			topK = n
+		}
+	}
+	res, err := h.check.Run(r.Context(), fh.Filename, data, r.MultipartForm.Value["folder"], roles, topK)
+	if err != nil {
+		var mismatch *usecase.TypeMismatchError
+		var failed *usecase.ExtractionError
+		switch {
+		case errors.As(err, &mismatch):
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		case errors.Is(err, context.DeadlineExceeded):
+			http.Error(w, err.Error(), http.StatusGatewayTimeout)
+		case errors.As(err, &failed):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		default:
+			http.Error(w, err.Error(), 400)
+		}
+		return
+	}
+	writeJSON(w, res)
+}
+
+// GetCheck returns a report kept by DOCSIM_CHECK_RETENTION.
+func (h *Handlers) GetCheck(w http.ResponseWriter, r *http.Request) {
+	res, err := h.check.Get(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "not found", 404)
+		return
+	}
+	writeJSON(w, res)
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+
+	"detector_plagio/backend/internal/usecase"
+)
+
+const docxContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// AnnotatedDocx serves GET /documents/{id}/annotated.docx: the submission
+// with its copied passages highlighted and a comment naming each source.
+func (h *Handlers) AnnotatedDocx(w http.ResponseWriter, r *http.Request) {
+	out, filename, err := h.export.AnnotatedDocx(r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrNotSubmission):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, os.ErrNotExist):
+			http.Error(w, "not found", 404)
+		default:
+			http.Error(w, err.Error(), 500)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", docxContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(out)))
+	w.Header().Set("Content-Disposition", "attachment; filename="+strconv.Quote(filename))
+	w.Write(out)
+}
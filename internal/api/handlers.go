@@ -3,14 +3,17 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
 
 	"detector_plagio/backend/internal/config"
+	"detector_plagio/backend/internal/domain"
 	"detector_plagio/backend/internal/ports"
 	"detector_plagio/backend/internal/service"
 	"detector_plagio/backend/internal/usecase"
@@ -19,46 +22,173 @@ import (
 )
 
 type Handlers struct {
-	cfg      *config.Config
-	repo     ports.DocumentRepo
-	userRepo ports.UserRepo
-	ingest   *usecase.Ingest
-	compare  *usecase.Compare
-	auth     *usecase.Auth
-	user     *usecase.User
-	jwt      *service.JWT
+	cfg         *config.Config
+	repo        ports.DocumentRepo
+	userRepo    ports.UserRepo
+	ingest      *usecase.Ingest
+	compare     *usecase.Compare
+	auth        *usecase.Auth
+	user        *usecase.User
+	uploads     *usecase.Uploads
+	metadata    *usecase.Metadata
+	quarantine  *usecase.Quarantine
+	revisions   *usecase.TextRevisions
+	adopt       *usecase.Adopt
+	reindex     *usecase.Reindexer
+	search      *usecase.Search
+	lookup      *usecase.Lookup
+	check       *usecase.Check
+	originality *usecase.Originality
+	reports     *usecase.Reports
+	export      *usecase.Export
+	jwt         *service.JWT
 }
 
-func NewHandlers(cfg *config.Config, repo ports.DocumentRepo, userRepo ports.UserRepo, ingest *usecase.Ingest, comp *usecase.Compare, auth *usecase.Auth, user *usecase.User, jwt *service.JWT) *Handlers {
-	return &Handlers{cfg: cfg, repo: repo, userRepo: userRepo, ingest: ingest, compare: comp, auth: auth, user: user, jwt: jwt}
+func NewHandlers(cfg *config.Config, repo ports.DocumentRepo, userRepo ports.UserRepo, ingest *usecase.Ingest, comp *usecase.Compare, auth *usecase.Auth, user *usecase.User, uploads *usecase.Uploads, metadata *usecase.Metadata, quarantine *usecase.Quarantine, revisions *usecase.TextRevisions, adopt *usecase.Adopt, reindex *usecase.Reindexer, search *usecase.Search, lookup *usecase.Lookup, check *usecase.Check, originality *usecase.Originality, reports *usecase.Reports, export *usecase.Export, jwt *service.JWT) *Handlers {
+	return &Handlers{cfg: cfg, repo: repo, userRepo: userRepo, ingest: ingest, compare: comp, auth: auth, user: user, uploads: uploads, metadata: metadata, quarantine: quarantine, revisions: revisions, adopt: adopt, reindex: reindex, search: search, lookup: lookup, check: check, originality: originality, reports: reports, export: export, jwt: jwt}
 }
 
+// Upload ingests one or more "file" parts. A single part answers with the
+// document, as before; several parts answer with one result per file.
 func (h *Handlers) Upload(w http.ResponseWriter, r *http.Request) {
 	log.Println("Upload handler called")
 	if err := r.ParseMultipartForm(h.cfg.MaxUploadMB << 20); err != nil {
 		http.Error(w, err.Error(), 400)
 		return
 	}
-	id := r.FormValue("id")
-	if id == "" {
-		id = uuid.NewString()
-	}
 	folder := r.FormValue("folder")
-	originalFilename := r.FormValue("originalFilename")
-	log.Printf("id: %s, folder: %s, originalFilename: %s", id, folder, originalFilename)
-	file, _, err := r.FormFile("file")
+	owner, verified := h.uploadOwner(r, r.FormValue("owner"))
+	role := r.FormValue("role")
+	files := r.MultipartForm.File["file"]
+	if len(files) == 0 {
+		http.Error(w, "file missing", 400)
+		return
+	}
+	names := r.MultipartForm.Value["originalFilename"]
+	items := make([]usecase.BatchItem, len(files))
+	for i, fh := range files {
+		name := fh.Filename
+		if len(names) == len(files) && names[i] != "" {
+			name = names[i]
+		}
+		items[i] = usecase.BatchItem{Doc: domain.Document{ID: uuid.NewString(), Folder: folder, OriginalFilename: name, Owner: owner, OwnerVerified: verified, Role: role}, Load: partLoader(fh)}
+	}
+
+	if len(files) == 1 {
+		id := r.FormValue("id")
+		if id == "" {
+			id = uuid.NewString()
+		}
+		doc := items[0].Doc
+		doc.ID = id
+		log.Printf("id: %s, folder: %s, originalFilename: %s", id, folder, doc.OriginalFilename)
+		b, err := items[0].Load()
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		doc, err = h.ingest.SaveAndIndex(r.Context(), doc, b)
+		if err != nil {
+			var mismatch *usecase.TypeMismatchError
+			if errors.As(err, &mismatch) {
+				http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+				return
+			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				http.Error(w, err.Error(), http.StatusGatewayTimeout)
+				return
+			}
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		writeJSON(w, doc)
+		return
+	}
+
+	log.Printf("folder: %s, %d files", folder, len(files))
+	writeJSON(w, h.ingest.SaveAndIndexBatch(r.Context(), items, h.cfg.UploadParallelism))
+}
+
+func partLoader(fh *multipart.FileHeader) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		f, err := fh.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return io.ReadAll(f)
+	}
+}
+
+// UploadArchive ingests every supported file of a ZIP sent as the "file"
+// part. The archive is spooled to a temp file, never held in memory.
+func (h *Handlers) UploadArchive(w http.ResponseWriter, r *http.Request) {
+	log.Println("UploadArchive handler called")
+	r.Body = http.MaxBytesReader(w, r.Body, h.cfg.ArchiveMaxMB<<20)
+	mr, err := r.MultipartReader()
 	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	var folder, owner, role string
+	var archive *os.File
+	defer func() {
+		if archive != nil {
+			archive.Close()
+			os.Remove(archive.Name())
+		}
+	}()
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		switch part.FormName() {
+		case "folder":
+			b, _ := io.ReadAll(io.LimitReader(part, 1024))
+			folder = string(b)
+		case "owner":
+			b, _ := io.ReadAll(io.LimitReader(part, 1024))
+			owner = string(b)
+		case "role":
+			b, _ := io.ReadAll(io.LimitReader(part, 1024))
+			role = string(b)
+		case "file":
+			if archive != nil {
+				http.Error(w, "only one archive per request", 400)
+				return
+			}
+			if archive, err = os.CreateTemp("", "upload_archive_*.zip"); err != nil {
+				http.Error(w, err.Error(), 500)
+				return
+			}
+			if _, err := io.Copy(archive, part); err != nil {
+				http.Error(w, err.Error(), 400)
+				return
+			}
+		}
+		part.Close()
+	}
+	if archive == nil {
 		http.Error(w, "file missing", 400)
 		return
 	}
-	defer file.Close()
-	b, _ := io.ReadAll(file)
-	doc, err := h.ingest.SaveAndIndex(id, folder, originalFilename, b)
+	info, err := archive.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	owner, verified := h.uploadOwner(r, owner)
+	report, err := h.ingest.IngestArchive(r.Context(), domain.Document{Folder: folder, Owner: owner, OwnerVerified: verified, Role: role}, archive, info.Size())
 	if err != nil {
 		http.Error(w, err.Error(), 400)
 		return
 	}
-	writeJSON(w, doc)
+	writeJSON(w, report)
 }
 
 func (h *Handlers) GetDoc(w http.ResponseWriter, r *http.Request) {
@@ -71,6 +201,47 @@ func (h *Handlers) GetDoc(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, d)
 }
 
+func (h *Handlers) GetDocMetadata(w http.ResponseWriter, r *http.Request) {
+	d, err := h.repo.Get(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "not found", 404)
+		return
+	}
+	writeJSON(w, map[string]any{
+		"id":               d.ID,
+		"owner":            d.Owner,
+		"originalFilename": d.OriginalFilename,
+		"metadata":         d.Metadata,
+	})
+}
+
+// MetadataCollisions lists documents of different owners sharing an author,
+// document GUID or template. ?fields=a,b narrows the properties checked.
+func (h *Handlers) MetadataCollisions(w http.ResponseWriter, r *http.Request) {
+	var fields []string
+	if f := r.URL.Query().Get("fields"); f != "" {
+		fields = strings.Split(f, ",")
+	}
+	res, err := h.metadata.Collisions(fields, r.URL.Query().Get("folder"))
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, res)
+}
+
+// AdoptOrphans repairs stored files missing their sidecar or text layer;
+// ?dryRun=true only reports them.
+func (h *Handlers) AdoptOrphans(w http.ResponseWriter, r *http.Request) {
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+	report, err := h.adopt.Run(r.Context(), dryRun)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, report)
+}
+
 func (h *Handlers) ListDocs(w http.ResponseWriter, r *http.Request) {
 	log.Println("ListDocs handler called")
 	docs, err := h.repo.List()
@@ -111,6 +282,12 @@ func (h *Handlers) DeleteDoc(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), 500)
 		return
 	}
+	if err := h.search.Unindex(id); err != nil {
+		log.Printf("DeleteDoc: removing %s from the index: %v", id, err)
+	}
+	if err := h.revisions.Delete(id); err != nil {
+		log.Printf("DeleteDoc: removing the text revisions of %s: %v", id, err)
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -136,7 +313,21 @@ func (h *Handlers) Similar(w http.ResponseWriter, r *http.Request) {
 			topK = n
 		}
 	}
-	ids, err := h.repo.ListIDs()
+	roles, err := roleFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	self, err := h.repo.Get(id)
+	if err != nil {
+		http.Error(w, "not found", 404)
+		return
+	}
+	if self.CorpusRole() != domain.RoleSubmission {
+		http.Error(w, usecase.ErrNotSubmission.Error(), http.StatusConflict)
+		return
+	}
+	docs, err := h.repo.List()
 	if err != nil {
 		http.Error(w, err.Error(), 500)
 		return
@@ -147,16 +338,19 @@ func (h *Handlers) Similar(w http.ResponseWriter, r *http.Request) {
 		Final                int    `json:"finalPercent"`
 		Near                 int    `json:"nearDuplicatePercent"`
 		Topic                int    `json:"topicSimilarityPercent"`
+		Role                 string `json:"role"`
 	}
 	var results []row
-	for _, other := range ids {
-		if other == id {
+	for _, d := range docs {
+		other := d.ID
+		if other == id || !roles[d.CorpusRole()] {
 			continue
 		}
-		res, err := h.compare.CompareTwo(id, other)
+		res, err := h.compare.CompareDocs(self, d)
 		if err == nil {
 			results = append(results, row{
 				ID:    other,
+				Role:  d.CorpusRole(),
 				Final: int(res.Final*100 + 0.5),
 				Near:  int(res.NearDuplicate*100 + 0.5),
 				Topic: int(res.TopicSimilarity*100 + 0.5),
@@ -309,7 +503,25 @@ func (h *Handlers) AuthMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// uploadOwner returns the signed-in user as the owner of an upload when the
+// request carries a valid token. Upload routes are public, so without one the
+// declared owner is kept but reported as unverified.
+func (h *Handlers) uploadOwner(r *http.Request, declared string) (string, bool) {
+	token, err := h.jwt.ValidateToken(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if err != nil || !token.Valid {
+		return declared, false
+	}
+	sub, err := token.Claims.GetSubject()
+	if err != nil || sub == "" {
+		return declared, false
+	}
+	if u, err := h.userRepo.GetByID(sub); err == nil {
+		return u.Username, true
+	}
+	return declared, false
+}
+
 func writeJSON(w http.ResponseWriter, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(v)
-}
\ No newline at end of file
+}
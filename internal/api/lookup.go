@@ -0,0 +1,36 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Lookup finds the documents containing passages of a pasted excerpt. The
+// body is either the excerpt itself (text/plain, folders as repeated
+// ?folder=) or {"text": "...", "folders": [...]}. Nothing is stored.
+func (h *Handlers) Lookup(w http.ResponseWriter, r *http.Request) {
+	body := http.MaxBytesReader(w, r.Body, h.cfg.MaxUploadMB<<20)
+	var p struct {
+		Text    string   `json:"text"`
+		Folders []string `json:"folders"`
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "text/plain") {
+		b, err := io.ReadAll(body)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		p.Text, p.Folders = string(b), r.URL.Query()["folder"]
+	} else if err := json.NewDecoder(body).Decode(&p); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	res, err := h.lookup.Find(p.Text, p.Folders)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	writeJSON(w, res)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"detector_plagio/backend/internal/usecase"
+)
+
+// CompareMatrix scores every pair of {"ids": [...]} or of every document in
+// {"folder": "..."}. The answer is JSON unless ?format=csv or an Accept of
+// text/csv asks for a CSV grid of one score (?metric=final|near|topic). A
+// folder is filtered by ?roles= like /similar.
+func (h *Handlers) CompareMatrix(w http.ResponseWriter, r *http.Request) {
+	var p struct {
+		IDs    []string `json:"ids"`
+		Folder string   `json:"folder"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	q := r.URL.Query()
+	asCSV := q.Get("format") == "csv" || strings.Contains(r.Header.Get("Accept"), "text/csv")
+	metric := q.Get("metric")
+	if metric == "" {
+		metric = "final"
+	}
+	if metric != "final" && metric != "near" && metric != "topic" {
+		http.Error(w, "metric must be final, near or topic", 400)
+		return
+	}
+
+	roles, err := roleFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	m, err := h.compare.Matrix(r.Context(), p.IDs, p.Folder, roles)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, err.Error(), 404)
+			return
+		}
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if !asCSV {
+		writeJSON(w, m)
+		return
+	}
+
+	scores := map[string][][]float64{"final": m.Final, "near": m.Near, "topic": m.Topic}[metric]
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="similarity-`+metric+`.csv"`)
+	cw := csv.NewWriter(w)
+	header := []string{"document"}
+	for _, d := range m.Docs {
+		header = append(header, d.OriginalFilename+" ("+d.ID+")")
+	}
+	cw.Write(header)
+	for i, d := range m.Docs {
+		row := []string{d.OriginalFilename + " (" + d.ID + ")"}
+		for _, s := range scores[i] {
+			row = append(row, strconv.FormatFloat(s, 'f', 4, 64))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+}
+
+// CompareFolders compares each document of a source folder against the
+// reference folders, e.g. {"source": "2025", "references": ["2024"]}.
+// topK defaults to 5 (at most 100) and threshold, which decides the flagged
+// count of each folder, to 0.5.
+func (h *Handlers) CompareFolders(w http.ResponseWriter, r *http.Request) {
+	var req usecase.FolderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if req.TopK <= 0 || req.TopK > 100 {
+		req.TopK = 5
+	}
+	if req.Threshold <= 0 {
+		req.Threshold = 0.5
+	}
+	res, err := h.compare.CompareFolders(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	writeJSON(w, res)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"time"
+)
+
+func (h *Handlers) ListQuarantine(w http.ResponseWriter, r *http.Request) {
+	items, err := h.quarantine.List()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, items)
+}
+
+func (h *Handlers) GetQuarantine(w http.ResponseWriter, r *http.Request) {
+	item, err := h.quarantine.Get(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "not found", 404)
+		return
+	}
+	writeJSON(w, item)
+}
+
+func (h *Handlers) RetryQuarantine(w http.ResponseWriter, r *http.Request) {
+	doc, err := h.quarantine.Retry(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			http.Error(w, "not found", 404)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	writeJSON(w, doc)
+}
+
+func (h *Handlers) RetryAllQuarantine(w http.ResponseWriter, r *http.Request) {
+	results, err := h.quarantine.RetryAll(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, results)
+}
+
+func (h *Handlers) PurgeQuarantineItem(w http.ResponseWriter, r *http.Request) {
+	if err := h.quarantine.Purge(r.PathValue("id")); err != nil {
+		if os.IsNotExist(err) {
+			http.Error(w, "not found", 404)
+			return
+		}
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PurgeQuarantine deletes every item, or with ?olderThan=72h only the old ones.
+func (h *Handlers) PurgeQuarantine(w http.ResponseWriter, r *http.Request) {
+	var age time.Duration
+	if v := r.URL.Query().Get("olderThan"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			http.Error(w, "invalid olderThan", 400)
+			return
+		}
+		age = d
+	}
+	n, err := h.quarantine.PurgeOlderThan(age)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, map[string]int{"purged": n})
+}
+
+func (h *Handlers) ExtractionMetrics(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.quarantine.Metrics()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, stats)
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"detector_plagio/backend/internal/usecase"
+)
+
+// StartReindex starts a background reindex. ?force=true reprocesses every
+// document, not only stale ones; ?throttle=200ms overrides the pause
+// between documents.
+func (h *Handlers) StartReindex(w http.ResponseWriter, r *http.Request) {
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+	throttle := h.cfg.ReindexThrottle
+	if v := r.URL.Query().Get("throttle"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			http.Error(w, "invalid throttle", 400)
+			return
+		}
+		throttle = d
+	}
+	job, err := h.reindex.Start(force, throttle)
+	if err != nil {
+		reindexError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	writeJSON(w, job)
+}
+
+func (h *Handlers) ReindexStatus(w http.ResponseWriter, r *http.Request) {
+	job, err := h.reindex.Status()
+	if err != nil {
+		reindexError(w, err)
+		return
+	}
+	writeJSON(w, job)
+}
+
+func (h *Handlers) StopReindex(w http.ResponseWriter, r *http.Request) {
+	job, err := h.reindex.Stop()
+	if err != nil {
+		reindexError(w, err)
+		return
+	}
+	writeJSON(w, job)
+}
+
+func (h *Handlers) ResumeReindex(w http.ResponseWriter, r *http.Request) {
+	job, err := h.reindex.Resume()
+	if err != nil {
+		reindexError(w, err)
+		return
+	}
+	writeJSON(w, job)
+}
+
+func (h *Handlers) AbortReindex(w http.ResponseWriter, r *http.Request) {
+	if err := h.reindex.Abort(); err != nil {
+		reindexError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func reindexError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrReindexRunning):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, usecase.ErrNoReindexJob):
+		http.Error(w, err.Error(), 404)
+	default:
+		http.Error(w, err.Error(), 500)
+	}
+}
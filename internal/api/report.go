@@ -0,0 +1,40 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+
+	"detector_plagio/backend/internal/usecase"
+)
+
+// CompareReport serves GET /compare/{id1}/{id2}/report?format=html|pdf as a
+// self-contained file; html is the default.
+func (h *Handlers) CompareReport(w http.ResponseWriter, r *http.Request) {
+	id1, id2 := r.PathValue("id1"), r.PathValue("id2")
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "html"
+	}
+	out, contentType, ext, err := h.reports.Render(id1, id2, format)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrUnknownReportFormat):
+			http.Error(w, err.Error(), 400)
+		case errors.Is(err, os.ErrNotExist):
+			http.Error(w, "not found", 404)
+		default:
+			http.Error(w, err.Error(), 500)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(out)))
+	disposition := "inline"
+	if format == "pdf" {
+		disposition = "attachment"
+	}
+	w.Header().Set("Content-Disposition", disposition+`; filename="comparison-`+id1+`-`+id2+ext+`"`)
+	w.Write(out)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SetDocText replaces the text layer of a document. The body is either the
+// text itself (text/plain) or {"text": "...", "note": "..."}; it requires a
+// logged-in user, who is recorded as the author of the correction.
+func (h *Handlers) SetDocText(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value("userID").(string)
+	var userName string
+	if u, err := h.userRepo.GetByID(userID); err == nil {
+		userName = u.Username
+	}
+	body := http.MaxBytesReader(w, r.Body, h.cfg.MaxUploadMB<<20)
+	var p struct {
+		Text string `json:"text"`
+		Note string `json:"note"`
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "text/plain") {
+		b, err := io.ReadAll(body)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		p.Text, p.Note = string(b), r.URL.Query().Get("note")
+	} else if err := json.NewDecoder(body).Decode(&p); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	doc, err := h.revisions.Override(r.PathValue("id"), p.Text, userID, userName, p.Note)
+	if err != nil {
+		if os.IsNotExist(err) {
+			http.Error(w, "not found", 404)
+			return
+		}
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	writeJSON(w, doc)
+}
+
+func (h *Handlers) ListTextRevisions(w http.ResponseWriter, r *http.Request) {
+	revs, err := h.revisions.List(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "not found", 404)
+		return
+	}
+	writeJSON(w, revs)
+}
+
+func (h *Handlers) GetTextRevision(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(r.PathValue("rev"))
+	if err != nil {
+		http.Error(w, "invalid revision", 400)
+		return
+	}
+	text, err := h.revisions.Text(r.PathValue("id"), n)
+	if err != nil {
+		http.Error(w, "not found", 404)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, text)
+}
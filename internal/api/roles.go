@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/usecase"
+)
+
+// roleFilter reads the roles a similarity query targets from ?roles=a,b.
+// Without it, reference and submission documents are searched and excluded
+// ones are not.
+func roleFilter(r *http.Request) (map[string]bool, error) {
+	v := r.URL.Query().Get("roles")
+	if v == "" {
+		return map[string]bool{domain.RoleReference: true, domain.RoleSubmission: true}, nil
+	}
+	roles := map[string]bool{}
+	for _, role := range strings.Split(v, ",") {
+		role = strings.TrimSpace(role)
+		if role == "" || !domain.ValidRole(role) {
+			return nil, errors.New("unknown role " + role)
+		}
+		roles[role] = true
+	}
+	return roles, nil
+}
+
+// SetDocRole changes the corpus role of a document: {"role": "reference"}.
+func (h *Handlers) SetDocRole(w http.ResponseWriter, r *http.Request) {
+	var p struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if p.Role == "" || !domain.ValidRole(p.Role) {
+		http.Error(w, "role must be reference, submission or excluded", 400)
+		return
+	}
+	id := r.PathValue("id")
+	if _, err := h.repo.Get(id); err != nil {
+		http.Error(w, "not found", 404)
+		return
+	}
+	doc, err := h.repo.Update(id, func(doc *domain.Document) error {
+		doc.Role = p.Role
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "not found", 404)
+			return
+		}
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, doc)
+}
+
+// Originality reports which reference and peer documents a submission draws
+// passages from.
+func (h *Handlers) Originality(w http.ResponseWriter, r *http.Request) {
+	rep, err := h.originality.Report(r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, usecase.ErrNotSubmission) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, "not found", 404)
+		return
+	}
+	writeJSON(w, rep)
+}
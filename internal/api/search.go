@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// Search serves GET /search?q=...&folder=...&limit=... from the inverted
+// index. folder may be repeated and roles filters like /similar; offsets in
+// the response are rune offsets into the document's textContent.
+func (h *Handlers) Search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := defaultSearchLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid limit", 400)
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+	roles, err := roleFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	res, err := h.search.Query(q.Get("q"), q["folder"], roles, limit)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	writeJSON(w, res)
+}
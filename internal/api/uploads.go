@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+	"detector_plagio/backend/internal/usecase"
+)
+
+// Resumable uploads follow the core tus 1.0 protocol (creation, HEAD, PATCH,
+// termination) plus POST /uploads/{id}/finalize, which ingests the file.
+const tusVersion = "1.0.0"
+
+func (h *Handlers) CreateUpload(w http.ResponseWriter, r *http.Request) {
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid Upload-Length", 400)
+		return
+	}
+	meta := parseUploadMetadata(r.Header.Get("Upload-Metadata"))
+	owner, verified := h.uploadOwner(r, meta["owner"])
+	s, err := h.uploads.Create(domain.UploadSession{Folder: meta["folder"], OriginalFilename: meta["filename"], Owner: owner, OwnerVerified: verified, Role: meta["role"], Length: length})
+	if err != nil {
+		if errors.Is(err, usecase.ErrUploadTooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	setUploadHeaders(w, s)
+	w.Header().Set("Location", "/uploads/"+s.ID)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *Handlers) UploadStatus(w http.ResponseWriter, r *http.Request) {
+	s, err := h.uploads.Get(r.PathValue("id"))
+	if err != nil {
+		uploadError(w, err)
+		return
+	}
+	setUploadHeaders(w, s)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handlers) PatchUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		http.Error(w, "Content-Type must be application/offset+octet-stream", http.StatusUnsupportedMediaType)
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid Upload-Offset", 400)
+		return
+	}
+	body := http.MaxBytesReader(w, r.Body, h.cfg.MaxUploadMB<<20)
+	s, err := h.uploads.Append(r.PathValue("id"), offset, body)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case err == nil:
+		setUploadHeaders(w, s)
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, ports.ErrOffsetMismatch), errors.Is(err, usecase.ErrUploadFinalized):
+		setUploadHeaders(w, s)
+		http.Error(w, err.Error(), http.StatusConflict)
+	case s.ID == "":
+		uploadError(w, err)
+	default:
+		// the chunk was cut short; the bytes received are kept and the
+		// offset header tells the client where to resume
+		log.Printf("PatchUpload: %s stopped at %d: %v", s.ID, s.Offset, err)
+		setUploadHeaders(w, s)
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "chunk exceeds upload size limit", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), 400)
+	}
+}
+
+func (h *Handlers) FinalizeUpload(w http.ResponseWriter, r *http.Request) {
+	doc, err := h.uploads.Finalize(r.Context(), r.PathValue("id"))
+	if err != nil {
+		var mismatch *usecase.TypeMismatchError
+		switch {
+		case errors.As(err, &mismatch):
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		case errors.Is(err, usecase.ErrUploadIncomplete):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, usecase.ErrUploadExpired), os.IsNotExist(err):
+			uploadError(w, err)
+		default:
+			http.Error(w, err.Error(), 400)
+		}
+		return
+	}
+	writeJSON(w, doc)
+}
+
+func (h *Handlers) CancelUpload(w http.ResponseWriter, r *http.Request) {
+	if err := h.uploads.Cancel(r.PathValue("id")); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func setUploadHeaders(w http.ResponseWriter, s domain.UploadSession) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Upload-Offset", strconv.FormatInt(s.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(s.Length, 10))
+	w.Header().Set("Upload-Expires", s.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+func uploadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrUploadExpired):
+		http.Error(w, err.Error(), http.StatusGone)
+	case errors.Is(err, ports.ErrOffsetMismatch):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case os.IsNotExist(err):
+		http.Error(w, "not found", 404)
+	default:
+		http.Error(w, err.Error(), 400)
+	}
+}
+
+// parseUploadMetadata decodes the tus "key base64value,key base64value" header.
+func parseUploadMetadata(h string) map[string]string {
+	out := map[string]string{}
+	for _, pair := range strings.Split(h, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			continue
+		}
+		if b, err := base64.StdEncoding.DecodeString(val); err == nil {
+			out[key] = string(b)
+		}
+	}
+	return out
+}
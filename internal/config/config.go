@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -12,6 +14,46 @@ type Config struct {
 	AllowedExtMap map[string]bool
 	JWTSecret     string
 	Port          int
+
+	// Files of one multi-file upload extracted at the same time.
+	UploadParallelism int
+
+	// Extraction limits for the external tools.
+	PDFTimeout     time.Duration
+	SofficeTimeout time.Duration
+	SofficeWorkers int
+
+	// Persistent unoserver listeners; zero keeps one-shot soffice only.
+	SofficeDaemons    int
+	SofficeDaemonPort int
+
+	// Resumable uploads: total size cap and how long an idle session lives.
+	ResumableMaxMB   int64
+	UploadSessionTTL time.Duration
+
+	// Guards for bulk ZIP uploads.
+	ArchiveMaxMB      int64
+	ArchiveMaxEntries int
+	ArchiveMaxTotalMB int64
+	ArchiveMaxRatio   int
+
+	// Pages with fewer extracted characters are treated as scanned. OCRCommand
+	// is run on those pages when set; see service.CommandOCR for placeholders.
+	LowTextCharsPerPage int
+	OCRCommand          string
+	OCRTimeout          time.Duration
+	OCRMaxPages         int
+
+	// Hot folders polled for new files; empty disables the watcher.
+	WatchDirs      []string
+	WatchInterval  time.Duration
+	WatchStableFor time.Duration
+
+	// Pause between documents of a reindex job, to leave room for uploads.
+	ReindexThrottle time.Duration
+
+	// How long the report of an ad-hoc /check is kept; zero keeps nothing.
+	CheckRetention time.Duration
 }
 
 func Load() *Config {
@@ -30,16 +72,43 @@ func Load() *Config {
 		}
 	}
 
+	// AllowedExtMap is filled in by main from the extractor registry.
 	cfg := &Config{
-		DataRoot:    root,
-		MaxUploadMB: 50,
-		AllowedExtMap: map[string]bool{
-			".pdf":  true,
-			".docx": true,
-			".txt":  true,
-		},
-		JWTSecret: jwtSecret,
-		Port:      port,
+		DataRoot:      root,
+		MaxUploadMB:   50,
+		AllowedExtMap: map[string]bool{},
+		JWTSecret:     jwtSecret,
+		Port:          port,
+
+		UploadParallelism: envInt("DOCSIM_UPLOAD_PARALLELISM", 4),
+
+		PDFTimeout:     envDuration("DOCSIM_PDF_TIMEOUT", 60*time.Second),
+		SofficeTimeout: envDuration("DOCSIM_SOFFICE_TIMEOUT", 120*time.Second),
+		SofficeWorkers: envInt("DOCSIM_SOFFICE_WORKERS", 2),
+
+		SofficeDaemons:    envInt("DOCSIM_SOFFICE_DAEMONS", 0),
+		SofficeDaemonPort: envInt("DOCSIM_SOFFICE_DAEMON_PORT", 2003),
+
+		ResumableMaxMB:   int64(envInt("DOCSIM_RESUMABLE_MAX_MB", 2048)),
+		UploadSessionTTL: envDuration("DOCSIM_UPLOAD_SESSION_TTL", 24*time.Hour),
+
+		ArchiveMaxMB:      int64(envInt("DOCSIM_ARCHIVE_MAX_MB", 500)),
+		ArchiveMaxEntries: envInt("DOCSIM_ARCHIVE_MAX_ENTRIES", 1000),
+		ArchiveMaxTotalMB: int64(envInt("DOCSIM_ARCHIVE_MAX_TOTAL_MB", 2048)),
+		ArchiveMaxRatio:   envInt("DOCSIM_ARCHIVE_MAX_RATIO", 100),
+
+		LowTextCharsPerPage: envInt("DOCSIM_LOW_TEXT_CHARS", 100),
+		OCRCommand:          os.Getenv("DOCSIM_OCR_COMMAND"),
+		OCRTimeout:          envDuration("DOCSIM_OCR_TIMEOUT", 120*time.Second),
+		OCRMaxPages:         envInt("DOCSIM_OCR_MAX_PAGES", 50),
+
+		WatchDirs:      envList("DOCSIM_WATCH_DIRS"),
+		WatchInterval:  envDuration("DOCSIM_WATCH_INTERVAL", 10*time.Second),
+		WatchStableFor: envDuration("DOCSIM_WATCH_STABLE", 5*time.Second),
+
+		ReindexThrottle: envDuration("DOCSIM_REINDEX_THROTTLE", 50*time.Millisecond),
+
+		CheckRetention: envDuration("DOCSIM_CHECK_RETENTION", 0),
 	}
 	_ = os.MkdirAll(filepath.Join(root, "docs"), 0755)
 	_ = os.MkdirAll(filepath.Join(root, "texts"), 0755)
@@ -47,5 +116,41 @@ func Load() *Config {
 	return cfg
 }
 
-func (c *Config) DocsPath() string  { return filepath.Join(c.DataRoot, "docs") }
-func (c *Config) TextsPath() string { return filepath.Join(c.DataRoot, "texts") }
+func (c *Config) DocsPath() string       { return filepath.Join(c.DataRoot, "docs") }
+func (c *Config) TextsPath() string      { return filepath.Join(c.DataRoot, "texts") }
+func (c *Config) UploadsPath() string    { return filepath.Join(c.DataRoot, "uploads") }
+func (c *Config) QuarantinePath() string { return filepath.Join(c.DataRoot, "quarantine") }
+func (c *Config) RevisionsPath() string  { return filepath.Join(c.DataRoot, "revisions") }
+func (c *Config) ReindexPath() string    { return filepath.Join(c.DataRoot, "reindex") }
+func (c *Config) IndexPath() string      { return filepath.Join(c.DataRoot, "index") }
+func (c *Config) ChecksPath() string     { return filepath.Join(c.DataRoot, "checks") }
+
+// envDuration parses a Go duration ("90s", "2m") and falls back to def when unset or invalid.
+func envDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return def
+}
+
+// envList splits a comma-separated value, dropping empty items.
+func envList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func envInt(key string, def int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return def
+}
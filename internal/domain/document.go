@@ -3,10 +3,74 @@ package domain
 type Document struct {
 	ID                string `json:"id"`
 	Folder            string `json:"folder"`
+	Owner             string `json:"owner,omitempty"`
+	// OwnerVerified is set when Owner comes from the uploader's login token
+	// rather than from a form field anyone can fill in.
+	OwnerVerified     bool   `json:"ownerVerified,omitempty"`
+	// Role is one of the Role* corpus roles; empty means RoleSubmission.
+	Role              string `json:"role,omitempty"`
 	Filename          string `json:"filename"`
 	OriginalFilename  string `json:"originalFilename"`
 	Size              int64  `json:"size"`
 	Ext               string `json:"ext"`
+	MimeType          string `json:"mimeType,omitempty"`
+	Encoding          string `json:"encoding,omitempty"`
+	SHA256            string `json:"sha256,omitempty"`
 	UpdatedAt         string `json:"updatedAt"`
 	TextContent       string `json:"textContent"`
+	// Metadata holds document properties (author, dates, producer...) keyed by the Meta* names.
+	Metadata          map[string]string `json:"metadata,omitempty"`
+	Integrity         *Integrity        `json:"integrity,omitempty"`
+	TextStats         *TextStats        `json:"textStats,omitempty"`
+	// Correction is set while the active text is a reviewer's revision.
+	Correction        *Correction       `json:"correction,omitempty"`
+	// Pipeline records the component versions that produced TextContent.
+	Pipeline          *PipelineVersion  `json:"pipeline,omitempty"`
+}
+
+// Keys of Document.Metadata. Extractors fill in whichever the format provides.
+const (
+	MetaAuthor         = "author"
+	MetaLastModifiedBy = "lastModifiedBy"
+	MetaCreated        = "created"
+	MetaModified       = "modified"
+	MetaApplication    = "application"
+	MetaProducer       = "producer"
+	MetaRevision       = "revision"
+	MetaTitle          = "title"
+	MetaCompany        = "company"
+	MetaTemplate       = "template"
+	MetaDocID          = "docId"
+	MetaRsidRoot       = "rsidRoot"
+	MetaPages          = "pages"
+)
+
+// PipelineVersion identifies the extractor, normalizer and index versions a
+// document went through; documents stamped with an older one need reindexing.
+type PipelineVersion struct {
+	Extractor  string `json:"extractor"`
+	Normalizer string `json:"normalizer"`
+	Index      string `json:"index"`
+}
+
+// Corpus roles. Reference documents (textbooks, templates, published
+// sources) are only ever sources of matches; submissions are what gets
+// checked; excluded documents are kept but left out of similarity queries.
+const (
+	RoleReference  = "reference"
+	RoleSubmission = "submission"
+	RoleExcluded   = "excluded"
+)
+
+// ValidRole reports whether r can be stored as Document.Role.
+func ValidRole(r string) bool {
+	return r == "" || r == RoleReference || r == RoleSubmission || r == RoleExcluded
+}
+
+// CorpusRole returns the role of d, defaulting to RoleSubmission.
+func (d Document) CorpusRole() string {
+	if d.Role == "" {
+		return RoleSubmission
+	}
+	return d.Role
 }
@@ -0,0 +1,29 @@
+package domain
+
+// Integrity counts the tricks used to hide copied text from the matcher.
+// Homoglyphs and zero-width characters are undone before normalization; the
+// counts stay on the document so that a reviewer can see they were there.
+type Integrity struct {
+	Homoglyphs  int      `json:"homoglyphs"`
+	ZeroWidth   int      `json:"zeroWidth"`
+	HiddenRuns  int      `json:"hiddenRuns"`
+	HiddenChars int      `json:"hiddenChars"`
+	Flags       []string `json:"flags"`
+}
+
+const (
+	FlagHomoglyphs = "homoglyphs"
+	FlagZeroWidth  = "zero-width"
+	FlagHiddenText = "hidden-text"
+	FlagLowText    = "low-text"
+)
+
+// TextStats records how much text extraction recovered. Page numbers are
+// 1-based; OCRPages lists the pages whose text came from the OCR stage.
+type TextStats struct {
+	Pages        int   `json:"pages"`
+	Chars        int   `json:"chars"`
+	CharsPerPage int   `json:"charsPerPage"`
+	LowTextPages []int `json:"lowTextPages,omitempty"`
+	OCRPages     []int `json:"ocrPages,omitempty"`
+}
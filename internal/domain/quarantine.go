@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// QuarantineItem is an upload whose extraction failed. The original bytes
+// are kept next to it so that it can be retried without resubmitting; the
+// ID is the document ID the upload would have had.
+type QuarantineItem struct {
+	ID               string    `json:"id"`
+	Folder           string    `json:"folder"`
+	Owner            string    `json:"owner,omitempty"`
+	OwnerVerified    bool      `json:"ownerVerified,omitempty"`
+	Role             string    `json:"role,omitempty"`
+	OriginalFilename string    `json:"originalFilename"`
+	Ext              string    `json:"ext"`
+	MimeType         string    `json:"mimeType,omitempty"`
+	Size             int64     `json:"size"`
+	Extractor        string    `json:"extractor"`
+	Error            string    `json:"error"`
+	Stderr           string    `json:"stderr,omitempty"`
+	Attempts         int       `json:"attempts"`
+	CreatedAt        time.Time `json:"createdAt"`
+	LastAttemptAt    time.Time `json:"lastAttemptAt"`
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// ComparisonReport is everything a report renderer prints about the
+// comparison of two documents.
+type ComparisonReport struct {
+	GeneratedAt time.Time
+	DocA, DocB  Document
+	Scores      []ReportScore
+	Passages    []ReportPassage
+	Segments    []ReportSegment
+}
+
+// ReportScore is one labelled, already formatted value of the comparison.
+type ReportScore struct {
+	Label string
+	Value string
+}
+
+// ReportPassage is a passage found in both documents, as rune offsets into
+// the TextContent of DocA and DocB.
+type ReportPassage struct {
+	AStart, AEnd int
+	BStart, BEnd int
+}
+
+// ReportSegment is a pair of similar sentences.
+type ReportSegment struct {
+	TextA, TextB string
+	Score        float64
+}
+
+// TextNote marks a range of a document's text, in rune offsets into its
+// TextContent, with a highlight color and a comment.
+type TextNote struct {
+	Start, End int
+	// Color is a WordprocessingML highlight name: yellow, cyan, green...
+	Color   string
+	Author  string
+	Comment string
+}
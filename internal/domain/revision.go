@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// Sources of a text revision.
+const (
+	TextSourceExtraction = "extraction"
+	TextSourceManual     = "manual"
+)
+
+// TextRevision is one stored version of a document's text layer. Revision 1
+// is the machine extraction; later ones are reviewer corrections.
+type TextRevision struct {
+	Revision   int       `json:"revision"`
+	Source     string    `json:"source"`
+	Author     string    `json:"author,omitempty"`
+	AuthorName string    `json:"authorName,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	Chars      int       `json:"chars"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Correction marks a document whose active text was supplied by a reviewer.
+type Correction struct {
+	Revision int       `json:"revision"`
+	By       string    `json:"by"`
+	ByName   string    `json:"byName,omitempty"`
+	At       time.Time `json:"at"`
+	Note     string    `json:"note,omitempty"`
+}
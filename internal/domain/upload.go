@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// UploadSession tracks a resumable upload whose bytes are spooled to disk
+// chunk by chunk until Offset reaches Length and it is finalized.
+type UploadSession struct {
+	ID               string    `json:"id"`
+	Folder           string    `json:"folder"`
+	OriginalFilename string    `json:"originalFilename"`
+	Owner            string    `json:"owner,omitempty"`
+	OwnerVerified    bool      `json:"ownerVerified,omitempty"`
+	Role             string    `json:"role,omitempty"`
+	Length           int64     `json:"length"`
+	Offset           int64     `json:"offset"`
+	CreatedAt        time.Time `json:"createdAt"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	// DocumentID is set once the upload has been ingested; the session is
+	// then kept until it expires so that a retried finalize gets the same
+	// document back.
+	DocumentID string `json:"documentId,omitempty"`
+}
+
+func (s UploadSession) Complete() bool { return s.Offset == s.Length }
@@ -0,0 +1,6 @@
+package ports
+
+// TextDecoder transcodes plain-text uploads to UTF-8 and names the source encoding.
+type TextDecoder interface {
+	Decode(data []byte) (utf8Text []byte, encoding string)
+}
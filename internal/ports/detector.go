@@ -0,0 +1,14 @@
+package ports
+
+// DetectedType describes what an upload actually contains, regardless of its name.
+type DetectedType struct {
+	MIME    string `json:"mime"`
+	Ext     string `json:"ext"`
+	Charset string `json:"charset,omitempty"`
+}
+
+type TypeDetector interface {
+	Detect(data []byte) DetectedType
+	// Compatible reports whether content of type t may be stored under the declared extension.
+	Compatible(declaredExt string, t DetectedType) bool
+}
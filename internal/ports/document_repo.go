@@ -4,6 +4,12 @@ import "detector_plagio/backend/internal/domain"
 
 type DocumentRepo interface {
 	Save(doc domain.Document, data []byte) error
+	// SaveMeta rewrites the sidecar of a stored document, leaving the file alone.
+	SaveMeta(doc domain.Document) error
+	// Update loads the sidecar of id, applies fn and saves the result while
+	// holding that document's lock, so concurrent read-modify-writes do not
+	// overwrite each other. Nothing is saved when fn returns an error.
+	Update(id string, fn func(doc *domain.Document) error) (domain.Document, error)
 	Get(id string) (domain.Document, error)
 	List() ([]domain.Document, error)
 	ListIDs() ([]string, error)
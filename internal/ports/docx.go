@@ -0,0 +1,10 @@
+package ports
+
+import "detector_plagio/backend/internal/domain"
+
+// DocxAnnotator highlights and comments ranges of a document's text in a
+// DOCX file. text is the document's TextContent, which the note offsets
+// refer to; original is the stored DOCX, or nil to generate one from text.
+type DocxAnnotator interface {
+	Annotate(original []byte, text string, notes []domain.TextNote) ([]byte, error)
+}
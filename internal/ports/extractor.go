@@ -1,6 +1,48 @@
 package ports
+
+import "context"
+
 type Extractor interface {
-	Extract(inputPath string) (string, error)
-	ExtractFromBytes(data []byte, ext string) (string, error)
+	// Extract and ExtractFromBytes must stop external tools when ctx is done.
+	Extract(ctx context.Context, inputPath string) (string, error)
+	ExtractFromBytes(ctx context.Context, data []byte, ext string) (string, error)
 	CanHandle(ext string) bool
+	// Extensions lists the lowercase extensions (with dot) the extractor accepts.
+	Extensions() []string
 }
+
+// ExtractorRegistry resolves the extractor for an extension and is the source
+// of truth for which extensions may be uploaded.
+type ExtractorRegistry interface {
+	For(ext string) Extractor
+	Extensions() []string
+}
+
+// Versioned is implemented by pipeline components whose output is stored;
+// the version changes whenever the same input would give different output.
+type Versioned interface {
+	Version() string
+}
+
+// MetadataExtractor is implemented by extractors that can also read the
+// document properties (author, dates, application...) of their formats.
+type MetadataExtractor interface {
+	ExtractMetadata(ctx context.Context, data []byte, ext string) (map[string]string, error)
+}
+
+// ToolError is returned when an external program fails; Stderr keeps its
+// diagnostic output apart from the error so that it can be recorded.
+type ToolError struct {
+	Tool   string
+	Err    error
+	Stderr string
+}
+
+func (e *ToolError) Error() string {
+	if e.Stderr == "" {
+		return e.Tool + ": " + e.Err.Error()
+	}
+	return e.Tool + ": " + e.Err.Error() + ": " + e.Stderr
+}
+
+func (e *ToolError) Unwrap() error { return e.Err }
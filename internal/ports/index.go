@@ -0,0 +1,32 @@
+package ports
+
+import "detector_plagio/backend/internal/domain"
+
+// Token is one indexed word of a text layer; Start and End are rune offsets.
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// TextIndex is a positional inverted index over the documents' text layers.
+type TextIndex interface {
+	// Put indexes doc.TextContent, replacing any earlier version of doc.
+	Put(doc domain.Document) error
+	Delete(id string) error
+	// Postings maps the IDs of the documents containing term to the token
+	// positions where it occurs.
+	Postings(term string) map[string][]int
+	// Tokens splits text the way the index does, so queries and offsets
+	// line up with the postings.
+	Tokens(text string) []Token
+	// Folder returns the folder a document was indexed under.
+	Folder(id string) (string, bool)
+	IDs() []string
+	// Current reports whether doc is indexed with its present TextContent.
+	Current(doc domain.Document) bool
+	// Flush makes every change so far durable in the compact on-disk form.
+	Flush() error
+	// Close flushes the index and releases its files; it cannot be used after.
+	Close() error
+}
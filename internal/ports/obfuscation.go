@@ -0,0 +1,9 @@
+package ports
+
+import "detector_plagio/backend/internal/domain"
+
+// ObfuscationAnalyzer undoes character-level evasion in extracted text and
+// reports it, together with any hidden text found in the original file.
+type ObfuscationAnalyzer interface {
+	Analyze(text string, data []byte, ext string) (canonical string, integrity domain.Integrity)
+}
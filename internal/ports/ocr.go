@@ -0,0 +1,9 @@
+package ports
+
+import "context"
+
+// OCR recognizes the text of pages that extraction left (nearly) empty.
+// Pages are 1-based; pages that fail are missing from the result.
+type OCR interface {
+	Recognize(ctx context.Context, data []byte, ext string, pages []int) (map[int]string, error)
+}
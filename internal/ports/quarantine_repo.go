@@ -0,0 +1,12 @@
+package ports
+
+import "detector_plagio/backend/internal/domain"
+
+type QuarantineRepo interface {
+	// Save creates or replaces the item and its original bytes.
+	Save(item domain.QuarantineItem, data []byte) error
+	Get(id string) (domain.QuarantineItem, error)
+	Data(id string) ([]byte, error)
+	List() ([]domain.QuarantineItem, error)
+	Delete(id string) error
+}
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"io"
+
+	"detector_plagio/backend/internal/domain"
+)
+
+// ReportRenderer prints a comparison report in one output format. The output
+// must be self-contained: no network access, no external assets.
+type ReportRenderer interface {
+	Render(w io.Writer, r domain.ComparisonReport) error
+	ContentType() string
+	Extension() string
+}
@@ -0,0 +1,13 @@
+package ports
+
+import "detector_plagio/backend/internal/domain"
+
+// TextRevisionRepo keeps every text layer of a document.
+type TextRevisionRepo interface {
+	// Add stores text as the next revision and returns it numbered.
+	Add(docID string, rev domain.TextRevision, text string) (domain.TextRevision, error)
+	List(docID string) ([]domain.TextRevision, error)
+	Text(docID string, revision int) (string, error)
+	// Delete removes every revision of a document.
+	Delete(docID string) error
+}
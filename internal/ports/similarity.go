@@ -22,5 +22,10 @@ type CosineTFIDFResult struct {
 type Similarity interface {
 	Jaccard(a, b []string) JaccardResult
 	CosineTFIDF(aTokens, bTokens []string) CosineTFIDFResult
+	// JaccardSets and CosineCounts take the shingle sets and term counts a
+	// batch computes once per document. They do not log, since a batch
+	// calls them for every pair.
+	JaccardSets(a, b map[string]bool) JaccardResult
+	CosineCounts(a, b map[string]int) CosineTFIDFResult
 	CompareSegments(textA, textB string) ([]MatchingSegment, error)
 }
@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"errors"
+	"io"
+
+	"detector_plagio/backend/internal/domain"
+)
+
+// ErrOffsetMismatch is returned when a chunk does not start where the upload currently ends.
+var ErrOffsetMismatch = errors.New("upload offset mismatch")
+
+// UploadRepo spools resumable uploads.
+type UploadRepo interface {
+	Create(s domain.UploadSession) error
+	Get(id string) (domain.UploadSession, error)
+	List() ([]domain.UploadSession, error)
+	// Append writes r at offset, which must equal the current offset, and
+	// never lets the upload grow past its declared length.
+	Append(id string, offset int64, r io.Reader) (domain.UploadSession, error)
+	// Open returns the assembled bytes; call release when done with them.
+	Open(id string) (data []byte, release func() error, err error)
+	// Finalized records the document the upload became and drops its bytes.
+	Finalized(id, documentID string) error
+	Delete(id string) error
+}
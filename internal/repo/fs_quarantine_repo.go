@@ -0,0 +1,104 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"detector_plagio/backend/internal/config"
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+// FSQuarantineRepo keeps each item in <DataRoot>/quarantine/<id>/ as an
+// info.json sidecar plus the original upload.
+type FSQuarantineRepo struct {
+	cfg *config.Config
+}
+
+func NewFSQuarantineRepo(cfg *config.Config) (ports.QuarantineRepo, error) {
+	if err := os.MkdirAll(cfg.QuarantinePath(), 0755); err != nil {
+		return nil, err
+	}
+	return &FSQuarantineRepo{cfg: cfg}, nil
+}
+
+func (r *FSQuarantineRepo) dir(id string) string      { return filepath.Join(r.cfg.QuarantinePath(), id) }
+func (r *FSQuarantineRepo) infoPath(id string) string { return filepath.Join(r.dir(id), "info.json") }
+func (r *FSQuarantineRepo) dataPath(id string) string { return filepath.Join(r.dir(id), "data") }
+
+func (r *FSQuarantineRepo) Save(item domain.QuarantineItem, data []byte) error {
+	if !idRe.MatchString(item.ID) {
+		return errors.New("invalid id")
+	}
+	if err := os.MkdirAll(r.dir(item.ID), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(r.dataPath(item.ID), data, 0644); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.infoPath(item.ID) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.infoPath(item.ID))
+}
+
+func (r *FSQuarantineRepo) Get(id string) (domain.QuarantineItem, error) {
+	var item domain.QuarantineItem
+	if !idRe.MatchString(id) {
+		return item, errors.New("invalid id")
+	}
+	b, err := os.ReadFile(r.infoPath(id))
+	if err != nil {
+		return item, err
+	}
+	err = json.Unmarshal(b, &item)
+	return item, err
+}
+
+func (r *FSQuarantineRepo) Data(id string) ([]byte, error) {
+	if !idRe.MatchString(id) {
+		return nil, errors.New("invalid id")
+	}
+	return os.ReadFile(r.dataPath(id))
+}
+
+// List returns the items oldest first.
+func (r *FSQuarantineRepo) List() ([]domain.QuarantineItem, error) {
+	ents, err := os.ReadDir(r.cfg.QuarantinePath())
+	if err != nil {
+		return nil, err
+	}
+	out := []domain.QuarantineItem{}
+	for _, e := range ents {
+		if !e.IsDir() {
+			continue
+		}
+		item, err := r.Get(e.Name())
+		if err != nil {
+			log.Printf("FSQuarantineRepo: skipping %s: %v", e.Name(), err)
+			continue
+		}
+		out = append(out, item)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *FSQuarantineRepo) Delete(id string) error {
+	if !idRe.MatchString(id) {
+		return errors.New("invalid id")
+	}
+	if _, err := os.Stat(r.dir(id)); err != nil {
+		return err
+	}
+	return os.RemoveAll(r.dir(id))
+}
@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"detector_plagio/backend/internal/config"
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+// FSTextRevisionRepo keeps revisions in <DataRoot>/revisions/<id>/ as
+// <n>.txt plus a <n>.json sidecar.
+type FSTextRevisionRepo struct {
+	cfg *config.Config
+	mu  sync.Mutex
+}
+
+func NewFSTextRevisionRepo(cfg *config.Config) (ports.TextRevisionRepo, error) {
+	if err := os.MkdirAll(cfg.RevisionsPath(), 0755); err != nil {
+		return nil, err
+	}
+	return &FSTextRevisionRepo{cfg: cfg}, nil
+}
+
+func (r *FSTextRevisionRepo) dir(id string) string { return filepath.Join(r.cfg.RevisionsPath(), id) }
+
+func (r *FSTextRevisionRepo) Add(docID string, rev domain.TextRevision, text string) (domain.TextRevision, error) {
+	if !idRe.MatchString(docID) {
+		return rev, errors.New("invalid id")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	revs, err := r.List(docID)
+	if err != nil {
+		return rev, err
+	}
+	rev.Revision = len(revs) + 1
+	if err := os.MkdirAll(r.dir(docID), 0755); err != nil {
+		return rev, err
+	}
+	base := filepath.Join(r.dir(docID), strconv.Itoa(rev.Revision))
+	if err := os.WriteFile(base+".txt", []byte(text), 0644); err != nil {
+		return rev, err
+	}
+	b, err := json.MarshalIndent(rev, "", "  ")
+	if err != nil {
+		return rev, err
+	}
+	return rev, os.WriteFile(base+".json", b, 0644)
+}
+
+// List returns the revisions in order; a document never corrected has none.
+func (r *FSTextRevisionRepo) List(docID string) ([]domain.TextRevision, error) {
+	if !idRe.MatchString(docID) {
+		return nil, errors.New("invalid id")
+	}
+	out := []domain.TextRevision{}
+	ents, err := os.ReadDir(r.dir(docID))
+	if os.IsNotExist(err) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range ents {
+		if !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(r.dir(docID), e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var rev domain.TextRevision
+		if err := json.Unmarshal(b, &rev); err != nil {
+			return nil, err
+		}
+		out = append(out, rev)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Revision < out[j].Revision })
+	return out, nil
+}
+
+func (r *FSTextRevisionRepo) Text(docID string, revision int) (string, error) {
+	if !idRe.MatchString(docID) {
+		return "", errors.New("invalid id")
+	}
+	b, err := os.ReadFile(filepath.Join(r.dir(docID), strconv.Itoa(revision)+".txt"))
+	return string(b), err
+}
+
+func (r *FSTextRevisionRepo) Delete(docID string) error {
+	if !idRe.MatchString(docID) {
+		return errors.New("invalid id")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return os.RemoveAll(r.dir(docID))
+}
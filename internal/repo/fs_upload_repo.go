@@ -0,0 +1,175 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"detector_plagio/backend/internal/config"
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+// FSUploadRepo keeps each session in <DataRoot>/uploads/<id>/ as an
+// info.json sidecar plus the raw bytes received so far.
+type FSUploadRepo struct {
+	cfg   *config.Config
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func NewFSUploadRepo(cfg *config.Config) (ports.UploadRepo, error) {
+	if err := os.MkdirAll(cfg.UploadsPath(), 0755); err != nil {
+		return nil, err
+	}
+	return &FSUploadRepo{cfg: cfg, locks: map[string]*sync.Mutex{}}, nil
+}
+
+// lock serializes PATCH requests racing on the same session.
+func (r *FSUploadRepo) lock(id string) func() {
+	r.mu.Lock()
+	l, ok := r.locks[id]
+	if !ok {
+		l = &sync.Mutex{}
+		r.locks[id] = l
+	}
+	r.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+func (r *FSUploadRepo) dir(id string) string      { return filepath.Join(r.cfg.UploadsPath(), id) }
+func (r *FSUploadRepo) infoPath(id string) string { return filepath.Join(r.dir(id), "info.json") }
+func (r *FSUploadRepo) dataPath(id string) string { return filepath.Join(r.dir(id), "data") }
+
+func (r *FSUploadRepo) Create(s domain.UploadSession) error {
+	if !idRe.MatchString(s.ID) {
+		return errors.New("invalid id")
+	}
+	if err := os.MkdirAll(r.dir(s.ID), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(r.dataPath(s.ID))
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return r.writeInfo(s)
+}
+
+func (r *FSUploadRepo) writeInfo(s domain.UploadSession) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.infoPath(s.ID) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.infoPath(s.ID))
+}
+
+func (r *FSUploadRepo) Get(id string) (domain.UploadSession, error) {
+	var s domain.UploadSession
+	if !idRe.MatchString(id) {
+		return s, errors.New("invalid id")
+	}
+	b, err := os.ReadFile(r.infoPath(id))
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(b, &s)
+	return s, err
+}
+
+func (r *FSUploadRepo) List() ([]domain.UploadSession, error) {
+	ents, err := os.ReadDir(r.cfg.UploadsPath())
+	if err != nil {
+		return nil, err
+	}
+	out := []domain.UploadSession{}
+	for _, e := range ents {
+		if !e.IsDir() {
+			continue
+		}
+		s, err := r.Get(e.Name())
+		if err != nil {
+			log.Printf("FSUploadRepo: skipping %s: %v", e.Name(), err)
+			continue
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func (r *FSUploadRepo) Append(id string, offset int64, src io.Reader) (domain.UploadSession, error) {
+	defer r.lock(id)()
+	s, err := r.Get(id)
+	if err != nil {
+		return s, err
+	}
+	if offset != s.Offset {
+		return s, ports.ErrOffsetMismatch
+	}
+	f, err := os.OpenFile(r.dataPath(id), os.O_WRONLY, 0644)
+	if err != nil {
+		return s, err
+	}
+	defer f.Close()
+	// Drop anything a previously interrupted chunk left past the recorded offset.
+	if err := f.Truncate(s.Offset); err != nil {
+		return s, err
+	}
+	if _, err := f.Seek(s.Offset, io.SeekStart); err != nil {
+		return s, err
+	}
+	n, copyErr := io.Copy(f, io.LimitReader(src, s.Length-s.Offset))
+	if err := f.Sync(); err != nil {
+		return s, err
+	}
+	// Keep whatever arrived before a dropped connection so the client can resume.
+	s.Offset += n
+	if err := r.writeInfo(s); err != nil {
+		return s, err
+	}
+	return s, copyErr
+}
+
+func (r *FSUploadRepo) Open(id string) ([]byte, func() error, error) {
+	if !idRe.MatchString(id) {
+		return nil, nil, errors.New("invalid id")
+	}
+	return mapFile(r.dataPath(id))
+}
+
+func (r *FSUploadRepo) Finalized(id, documentID string) error {
+	defer r.lock(id)()
+	s, err := r.Get(id)
+	if err != nil {
+		return err
+	}
+	s.DocumentID = documentID
+	if err := r.writeInfo(s); err != nil {
+		return err
+	}
+	if err := os.Remove(r.dataPath(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (r *FSUploadRepo) Delete(id string) error {
+	if !idRe.MatchString(id) {
+		return errors.New("invalid id")
+	}
+	defer r.lock(id)()
+	err := os.RemoveAll(r.dir(id))
+	r.mu.Lock()
+	delete(r.locks, id)
+	r.mu.Unlock()
+	return err
+}
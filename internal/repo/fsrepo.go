@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"detector_plagio/backend/internal/config"
@@ -17,9 +18,28 @@ import (
 
 var idRe = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
 
-type FSRepo struct{ cfg *config.Config }
+type FSRepo struct {
+	cfg   *config.Config
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func NewFSRepo(cfg *config.Config) ports.DocumentRepo {
+	return &FSRepo{cfg: cfg, locks: map[string]*sync.Mutex{}}
+}
 
-func NewFSRepo(cfg *config.Config) ports.DocumentRepo { return &FSRepo{cfg: cfg} }
+// lock serializes sidecar writes to the same document.
+func (r *FSRepo) lock(id string) func() {
+	r.mu.Lock()
+	l, ok := r.locks[id]
+	if !ok {
+		l = &sync.Mutex{}
+		r.locks[id] = l
+	}
+	r.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
 
 func (r *FSRepo) Save(doc domain.Document, data []byte) error {
 	log.Printf("Saving document: %+v", doc)
@@ -30,6 +50,7 @@ func (r *FSRepo) Save(doc domain.Document, data []byte) error {
 	if !r.cfg.AllowedExtMap[ext] {
 		return errors.New("unsupported extension")
 	}
+	defer r.lock(doc.ID)()
 	dst := filepath.Join(r.cfg.DocsPath(), doc.ID+ext)
 	if err := os.WriteFile(dst, data, 0644); err != nil {
 		return err
@@ -43,6 +64,39 @@ func (r *FSRepo) Save(doc domain.Document, data []byte) error {
 	return nil
 }
 
+func (r *FSRepo) SaveMeta(doc domain.Document) error {
+	if !idRe.MatchString(doc.ID) {
+		return errors.New("invalid id")
+	}
+	defer r.lock(doc.ID)()
+	return r.saveMeta(&doc)
+}
+
+func (r *FSRepo) Update(id string, fn func(doc *domain.Document) error) (domain.Document, error) {
+	if !idRe.MatchString(id) {
+		return domain.Document{}, errors.New("invalid id")
+	}
+	defer r.lock(id)()
+	doc, err := r.Get(id)
+	if err != nil {
+		return doc, err
+	}
+	if err := fn(&doc); err != nil {
+		return doc, err
+	}
+	doc.ID = id
+	return doc, r.saveMeta(&doc)
+}
+
+func (r *FSRepo) saveMeta(doc *domain.Document) error {
+	doc.UpdatedAt = time.Now().Format(time.RFC3339)
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(r.cfg.DocsPath(), doc.ID+".json"), b, 0644)
+}
+
 func (r *FSRepo) Get(id string) (domain.Document, error) {
 	var d domain.Document
 	if !idRe.MatchString(id) {
@@ -188,6 +242,12 @@ func (r *FSRepo) Delete(id string) error {
 	if !idRe.MatchString(id) {
 		return errors.New("invalid id")
 	}
+	defer r.lock(id)()
+	defer func() {
+		r.mu.Lock()
+		delete(r.locks, id)
+		r.mu.Unlock()
+	}()
 	rawPath, txtPath := r.PathFor(id)
 	if rawPath == "" {
 		return os.ErrNotExist
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package repo
+
+import "os"
+
+func mapFile(path string) ([]byte, func() error, error) {
+	data, err := os.ReadFile(path)
+	return data, func() error { return nil }, err
+}
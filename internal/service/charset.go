@@ -0,0 +1,130 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"detector_plagio/backend/internal/ports"
+)
+
+const (
+	EncUTF8    = "utf-8"
+	EncUTF16LE = "utf-16le"
+	EncUTF16BE = "utf-16be"
+	EncCP1252  = "windows-1252"
+	EncLatin1  = "iso-8859-1"
+)
+
+// cp1252High holds the characters Windows-1252 puts in 0x80-0x9F, where
+// ISO-8859-1 has C1 control codes. Zero marks the five undefined slots.
+var cp1252High = [32]rune{
+	0x20AC, 0, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0, 0x017D, 0,
+	0, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0, 0x017E, 0x0178,
+}
+
+type CharsetDecoder struct{}
+
+func NewCharsetDecoder() ports.TextDecoder { return &CharsetDecoder{} }
+
+func (d *CharsetDecoder) Decode(data []byte) ([]byte, string) {
+	enc := detectEncoding(data)
+	return []byte(decodeText(data, enc)), enc
+}
+
+// detectEncoding looks for a BOM first, then for the NUL pattern of BOM-less
+// UTF-16, then validates UTF-8. Anything else is 8-bit: Windows-1252 when it
+// uses the 0x80-0x9F range (curly quotes, dashes, euro), otherwise Latin-1.
+func detectEncoding(b []byte) string {
+	switch {
+	case bytes.HasPrefix(b, []byte{0xEF, 0xBB, 0xBF}):
+		return EncUTF8
+	case bytes.HasPrefix(b, []byte{0xFF, 0xFE}):
+		return EncUTF16LE
+	case bytes.HasPrefix(b, []byte{0xFE, 0xFF}):
+		return EncUTF16BE
+	}
+	if enc := guessUTF16(b); enc != "" {
+		return enc
+	}
+	if utf8.Valid(b) {
+		return EncUTF8
+	}
+	for _, c := range b {
+		if c >= 0x80 && c <= 0x9F && cp1252High[c-0x80] != 0 {
+			return EncCP1252
+		}
+	}
+	return EncLatin1
+}
+
+// guessUTF16 recognizes BOM-less UTF-16 from mostly-Latin text, where every
+// other byte is NUL.
+func guessUTF16(b []byte) string {
+	n := min(len(b), sniffLen) &^ 1
+	if n < 4 {
+		return ""
+	}
+	var evenZero, oddZero int
+	for i := 0; i < n; i += 2 {
+		if b[i] == 0 {
+			evenZero++
+		}
+		if b[i+1] == 0 {
+			oddZero++
+		}
+	}
+	pairs := n / 2
+	switch {
+	case oddZero*10 >= pairs*7 && evenZero*10 < pairs:
+		return EncUTF16LE
+	case evenZero*10 >= pairs*7 && oddZero*10 < pairs:
+		return EncUTF16BE
+	}
+	return ""
+}
+
+// dosEOF is the Ctrl-Z some DOS-era editors append to text files.
+const dosEOF = 0x1A
+
+// decodeText converts b from enc to a UTF-8 string, dropping any BOM and a
+// trailing DOS end-of-file mark.
+func decodeText(b []byte, enc string) string {
+	if enc != EncUTF16LE && enc != EncUTF16BE {
+		b = bytes.TrimSuffix(b, []byte{dosEOF})
+	}
+	switch enc {
+	case EncUTF16LE, EncUTF16BE:
+		var order binary.ByteOrder = binary.LittleEndian
+		if enc == EncUTF16BE {
+			order = binary.BigEndian
+		}
+		u := make([]uint16, 0, len(b)/2)
+		for i := 0; i+1 < len(b); i += 2 {
+			u = append(u, order.Uint16(b[i:]))
+		}
+		if len(u) > 0 && u[0] == 0xFEFF {
+			u = u[1:]
+		}
+		return string(utf16.Decode(u))
+	case EncCP1252, EncLatin1:
+		var sb strings.Builder
+		sb.Grow(len(b) + len(b)/8)
+		for _, c := range b {
+			sb.WriteRune(decodeByte(c, enc == EncCP1252))
+		}
+		return sb.String()
+	}
+	return strings.ToValidUTF8(string(bytes.TrimPrefix(b, []byte{0xEF, 0xBB, 0xBF})), "�")
+}
+
+func decodeByte(c byte, cp1252 bool) rune {
+	if cp1252 && c >= 0x80 && c <= 0x9F && cp1252High[c-0x80] != 0 {
+		return cp1252High[c-0x80]
+	}
+	return rune(c)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+func utf16Bytes(s string, order binary.ByteOrder, bom bool) []byte {
+	u := utf16.Encode([]rune(s))
+	if bom {
+		u = append([]uint16{0xFEFF}, u...)
+	}
+	b := make([]byte, 2*len(u))
+	for i, v := range u {
+		order.PutUint16(b[2*i:], v)
+	}
+	return b
+}
+
+func TestCharsetDecode(t *testing.T) {
+	const text = "Año de la canción: “niño” — 5 €"
+	cases := []struct {
+		name string
+		data []byte
+		enc  string
+		want string
+	}{
+		{"utf-8", []byte(text), EncUTF8, text},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, text...), EncUTF8, text},
+		{"utf-16le bom", utf16Bytes(text, binary.LittleEndian, true), EncUTF16LE, text},
+		{"utf-16be bom", utf16Bytes(text, binary.BigEndian, true), EncUTF16BE, text},
+		{"utf-16le no bom", utf16Bytes("plain ascii text here", binary.LittleEndian, false), EncUTF16LE, "plain ascii text here"},
+		{"utf-16be no bom", utf16Bytes("plain ascii text here", binary.BigEndian, false), EncUTF16BE, "plain ascii text here"},
+		{"cp1252", []byte("A\xf1o: \x93ni\xf1o\x94 \x97 5 \x80"), EncCP1252, "Año: “niño” — 5 €"},
+		{"latin-1", []byte("canci\xf3n y ni\xf1o"), EncLatin1, "canción y niño"},
+		{"cp1252 undefined slot", []byte("a\x81b\xe9"), EncLatin1, "a\u0081bé"},
+		{"utf-8 dos eof", []byte("fin\r\n\x1a"), EncUTF8, "fin\r\n"},
+		{"latin-1 dos eof", []byte("ca\xf1\xf3n\x1a"), EncLatin1, "cañón"},
+	}
+	d := NewCharsetDecoder()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, enc := d.Decode(c.data)
+			if enc != c.enc || string(got) != c.want {
+				t.Errorf("Decode = %q (%s), want %q (%s)", got, enc, c.want, c.enc)
+			}
+		})
+	}
+}
+
+func TestGuessUTF16(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"too short", []byte{'a', 0}, ""},
+		{"ascii", []byte("hello world"), ""},
+		{"le", utf16Bytes("hola mundo", binary.LittleEndian, false), EncUTF16LE},
+		{"be", utf16Bytes("hola mundo", binary.BigEndian, false), EncUTF16BE},
+		{"binary zeros", make([]byte, 64), ""},
+	}
+	for _, c := range cases {
+		if got := guessUTF16(c.data); got != c.want {
+			t.Errorf("%s: guessUTF16 = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestSniffTextTruncatedRune(t *testing.T) {
+	// a 2-byte "ñ" cut in half by the sniff window is still UTF-8
+	head := []byte("ni\xc3")
+	if enc, ok := sniffText(head, true); !ok || enc != EncUTF8 {
+		t.Errorf("sniffText = %q, %v; want %q, true", enc, ok, EncUTF8)
+	}
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+// DocxAnnotator edits word/document.xml textually: runs made of a single
+// w:t are split where a note starts or ends, the marked pieces get a
+// w:highlight and each note becomes a w:comment anchored on its range.
+// Other runs (fields, tabs, drawings...) are copied untouched, so their
+// text may end up outside a highlight but the document stays intact.
+type DocxAnnotator struct{}
+
+func NewDocxAnnotator() ports.DocxAnnotator { return &DocxAnnotator{} }
+
+const (
+	wordNS              = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	commentsRelType     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments"
+	commentsContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.comments+xml"
+)
+
+var (
+	// a run, or the end of a paragraph; "<w:r " and "<w:r>" leave out <w:rPr>
+	docxRunRe    = regexp.MustCompile(`(?s)<w:r(?:\s[^>]*)?>.*?</w:r>|</w:p>`)
+	docxOpenRe   = regexp.MustCompile(`^<w:r(?:\s[^>]*)?>`)
+	docxRPrRe    = regexp.MustCompile(`(?s)^\s*<w:rPr>.*?</w:rPr>`)
+	docxPlainRe  = regexp.MustCompile(`(?s)^\s*<w:t(?:\s[^>]*)?>([^<]*)</w:t>\s*$`)
+	docxTextRe   = regexp.MustCompile(`(?s)<w:t(?:\s[^>]*)?>([^<]*)</w:t>`)
+	docxIDRe     = regexp.MustCompile(`w:id="(\d+)"`)
+	highlightRe  = regexp.MustCompile(`<w:highlight\s[^>]*/>`)
+	afterHighRe  = regexp.MustCompile(`<w:(?:u|effect|bdr|shd|fitText|vertAlign|rtl|cs|em|lang|eastAsianLayout|specVanish|oMath|rPrChange)[\s/>]`)
+	xmlTextQuote = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
+
+// docxRun is a run of the body; only plain runs (rPr plus one w:t) can be split.
+type docxRun struct {
+	raw, open, rPr string
+	text           []rune
+	plain          bool
+}
+
+// streamChar locates a character of the body text; run is -1 for the
+// space standing for a paragraph break.
+type streamChar struct {
+	r        rune
+	run, off int
+}
+
+func (a *DocxAnnotator) Annotate(original []byte, text string, notes []domain.TextNote) ([]byte, error) {
+	if original == nil {
+		original = generatedDocx(text)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(original), int64(len(original)))
+	if err != nil {
+		return nil, err
+	}
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		switch f.Name {
+		case "word/document.xml", "word/comments.xml", "word/_rels/document.xml.rels", "[Content_Types].xml":
+			b, err := readZipFile(f)
+			if err != nil {
+				return nil, err
+			}
+			parts[f.Name] = string(b)
+		}
+	}
+	body, ok := parts["word/document.xml"]
+	if !ok {
+		return nil, errors.New("docx has no word/document.xml")
+	}
+
+	firstID := 0
+	for _, m := range docxIDRe.FindAllStringSubmatch(parts["word/comments.xml"], -1) {
+		if n, _ := strconv.Atoi(m[1]); n >= firstID {
+			firstID = n + 1
+		}
+	}
+	body, used := annotateBody(body, text, notes, firstID)
+	parts["word/document.xml"] = body
+	parts["word/comments.xml"] = commentsPart(parts["word/comments.xml"], notes, used, firstID)
+	parts["word/_rels/document.xml.rels"] = withCommentsRel(parts["word/_rels/document.xml.rels"])
+	parts["[Content_Types].xml"] = withCommentsType(parts["[Content_Types].xml"])
+
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for _, f := range zr.File {
+		if _, changed := parts[f.Name]; changed {
+			continue
+		}
+		if err := zw.Copy(f); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range []string{"[Content_Types].xml", "word/_rels/document.xml.rels", "word/document.xml", "word/comments.xml"} {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(w, parts[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// annotateBody marks the notes in document.xml and returns it with the notes
+// that could be placed; note i gets the comment id firstID+i.
+func annotateBody(body, text string, notes []domain.TextNote, firstID int) (string, []bool) {
+	// split the body into verbatim gaps and runs
+	var runs []*docxRun
+	var gaps []string
+	var stream []streamChar
+	last := 0
+	for _, loc := range docxRunRe.FindAllStringIndex(body, -1) {
+		raw := body[loc[0]:loc[1]]
+		if raw == "</w:p>" {
+			stream = append(stream, streamChar{r: ' ', run: -1})
+			continue
+		}
+		gaps = append(gaps, body[last:loc[0]])
+		last = loc[1]
+		run := &docxRun{raw: raw, open: docxOpenRe.FindString(raw)}
+		inner := strings.TrimSuffix(raw[len(run.open):], "</w:r>")
+		run.rPr = docxRPrRe.FindString(inner)
+		if m := docxPlainRe.FindStringSubmatch(inner[len(run.rPr):]); m != nil {
+			run.plain, run.text = true, []rune(html.UnescapeString(m[1]))
+		} else {
+			for _, t := range docxTextRe.FindAllStringSubmatch(inner, -1) {
+				run.text = append(run.text, []rune(html.UnescapeString(t[1]))...)
+			}
+		}
+		for off, r := range run.text {
+			stream = append(stream, streamChar{r: r, run: len(runs), off: off})
+		}
+		runs = append(runs, run)
+	}
+	tail := body[last:]
+
+	// mark every character of a note's range, first note wins
+	marks := make([][]int, len(runs))
+	for i, run := range runs {
+		marks[i] = make([]int, len(run.text))
+		for k := range marks[i] {
+			marks[i][k] = -1
+		}
+	}
+	bounds := alignWords(stream, text)
+	used := make([]bool, len(notes))
+	first := make([]int, len(notes))
+	lastMark := make([]int, len(notes))
+	for n, note := range notes {
+		from, to := -1, -1
+		for _, b := range bounds {
+			if b.tStart >= note.Start && b.tEnd <= note.End {
+				if from < 0 {
+					from = b.sStart
+				}
+				to = b.sEnd
+			}
+		}
+		for s := from; s >= 0 && s < to; s++ {
+			c := stream[s]
+			if c.run < 0 || !runs[c.run].plain || marks[c.run][c.off] >= 0 {
+				continue
+			}
+			marks[c.run][c.off] = n
+			if !used[n] {
+				used[n], first[n] = true, s
+			}
+			lastMark[n] = s
+		}
+	}
+	// stream index of the first character of each run
+	runStart := make([]int, len(runs))
+	for s := len(stream) - 1; s >= 0; s-- {
+		if c := stream[s]; c.run >= 0 && c.off == 0 {
+			runStart[c.run] = s
+		}
+	}
+
+	var out strings.Builder
+	for i, run := range runs {
+		out.WriteString(gaps[i])
+		if !run.plain {
+			out.WriteString(run.raw)
+			continue
+		}
+		for k := 0; k < len(run.text); {
+			n := marks[i][k]
+			end := k
+			for end < len(run.text) && marks[i][end] == n {
+				end++
+			}
+			s := runStart[i] + k
+			if n >= 0 && s == first[n] {
+				fmt.Fprintf(&out, `<w:commentRangeStart w:id="%d"/>`, firstID+n)
+			}
+			rPr := run.rPr
+			if n >= 0 {
+				rPr = withHighlight(rPr, notes[n].Color)
+			}
+			fmt.Fprintf(&out, `%s%s<w:t xml:space="preserve">%s</w:t></w:r>`, run.open, rPr, xmlTextQuote.Replace(string(run.text[k:end])))
+			if n >= 0 && runStart[i]+end-1 == lastMark[n] {
+				fmt.Fprintf(&out, `<w:commentRangeEnd w:id="%d"/><w:r><w:commentReference w:id="%d"/></w:r>`, firstID+n, firstID+n)
+			}
+			k = end
+		}
+	}
+	out.WriteString(tail)
+	return out.String(), used
+}
+
+// wordBounds pairs a word of the text with the same word in the body stream.
+type wordBounds struct{ tStart, tEnd, sStart, sEnd int }
+
+// alignWords matches the words of text, as the Normalizer left them, with
+// the words of the DOCX body. Extraction may drop or add a few words
+// (headers, hidden text, fields), so on a mismatch it skips ahead on
+// whichever side resynchronizes sooner.
+func alignWords(stream []streamChar, text string) []wordBounds {
+	type word struct {
+		w          string
+		start, end int
+	}
+	var sw []word
+	for s := 0; s < len(stream); {
+		if !IsWordRune(stream[s].r) {
+			s++
+			continue
+		}
+		e := s
+		var b strings.Builder
+		for e < len(stream) && IsWordRune(stream[e].r) {
+			b.WriteRune(stream[e].r)
+			e++
+		}
+		sw = append(sw, word{strings.ToLower(b.String()), s, e})
+		s = e
+	}
+	var tw []word
+	pos := 0
+	for _, w := range strings.Split(text, " ") {
+		n := utf8.RuneCountInString(w)
+		if n > 0 {
+			tw = append(tw, word{w, pos, pos + n})
+		}
+		pos += n + 1
+	}
+
+	const window = 30
+	var out []wordBounds
+	i, j := 0, 0
+	for i < len(sw) && j < len(tw) {
+		if sw[i].w == tw[j].w {
+			out = append(out, wordBounds{tw[j].start, tw[j].end, sw[i].start, sw[i].end})
+			i, j = i+1, j+1
+			continue
+		}
+		skipS, skipT := -1, -1
+		for d := 1; d <= window && (skipS < 0 && skipT < 0); d++ {
+			if i+d < len(sw) && sw[i+d].w == tw[j].w {
+				skipS = d
+			} else if j+d < len(tw) && tw[j+d].w == sw[i].w {
+				skipT = d
+			}
+		}
+		switch {
+		case skipS > 0:
+			i += skipS
+		case skipT > 0:
+			j += skipT
+		default:
+			i, j = i+1, j+1
+		}
+	}
+	return out
+}
+
+// withHighlight sets the highlight of a run, keeping the element order of
+// the schema, which Word enforces.
+func withHighlight(rPr, color string) string {
+	h := `<w:highlight w:val="` + color + `"/>`
+	if rPr == "" {
+		return "<w:rPr>" + h + "</w:rPr>"
+	}
+	rPr = highlightRe.ReplaceAllString(rPr, "")
+	if loc := afterHighRe.FindStringIndex(rPr); loc != nil {
+		return rPr[:loc[0]] + h + rPr[loc[0]:]
+	}
+	return strings.Replace(rPr, "</w:rPr>", h+"</w:rPr>", 1)
+}
+
+func commentsPart(existing string, notes []domain.TextNote, used []bool, firstID int) string {
+	var b strings.Builder
+	date := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+	for n, note := range notes {
+		if !used[n] {
+			continue
+		}
+		fmt.Fprintf(&b, `<w:comment w:id="%d" w:author="%s" w:date="%s">`, firstID+n, xmlTextQuote.Replace(note.Author), date)
+		for _, line := range strings.Split(note.Comment, "\n") {
+			fmt.Fprintf(&b, `<w:p><w:r><w:t xml:space="preserve">%s</w:t></w:r></w:p>`, xmlTextQuote.Replace(line))
+		}
+		b.WriteString("</w:comment>")
+	}
+	if strings.Contains(existing, "</w:comments>") {
+		return strings.Replace(existing, "</w:comments>", b.String()+"</w:comments>", 1)
+	}
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<w:comments xmlns:w="` + wordNS + `">` + b.String() + `</w:comments>`
+}
+
+func withCommentsRel(rels string) string {
+	if strings.Contains(rels, commentsRelType) {
+		return rels
+	}
+	rel := `<Relationship Id="rIdDocSimComments" Type="` + commentsRelType + `" Target="comments.xml"/>`
+	if !strings.Contains(rels, "</Relationships>") {
+		return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + rel + `</Relationships>`
+	}
+	return strings.Replace(rels, "</Relationships>", rel+"</Relationships>", 1)
+}
+
+func withCommentsType(types string) string {
+	if strings.Contains(types, `"/word/comments.xml"`) {
+		return types
+	}
+	return strings.Replace(types, "</Types>", `<Override PartName="/word/comments.xml" ContentType="`+commentsContentType+`"/></Types>`, 1)
+}
+
+// generatedDocx packs text as a one-paragraph DOCX, for documents whose
+// original is not a DOCX.
+func generatedDocx(text string) []byte {
+	files := []struct{ name, body string }{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/></Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/></Relationships>`},
+		{"word/_rels/document.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"></Relationships>`},
+		{"word/document.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="` + wordNS + `"><w:body><w:p><w:r><w:t xml:space="preserve">` + xmlTextQuote.Replace(text) + `</w:t></w:r></w:p><w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1417" w:right="1701" w:bottom="1417" w:left="1701" w:header="708" w:footer="708" w:gutter="0"/></w:sectPr></w:body></w:document>`},
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, _ := zw.Create(f.name)
+		io.WriteString(w, f.body)
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"html"
+	"io"
+	"strings"
+	"testing"
+
+	"detector_plagio/backend/internal/domain"
+)
+
+// testDocx packs parts into a DOCX; document is the inside of w:body.
+func testDocx(t *testing.T, document string, parts map[string]string) []byte {
+	t.Helper()
+	files := map[string]string{
+		"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="xml" ContentType="application/xml"/></Types>`,
+		"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"></Relationships>`,
+		"word/document.xml":     `<w:document xmlns:w="` + wordNS + `"><w:body>` + document + `</w:body></w:document>`,
+		"word/media/image1.png": "not really a png",
+	}
+	for name, body := range parts {
+		files[name] = body
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, body)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// docxParts unpacks every part of an annotated DOCX.
+func docxParts(t *testing.T, docx []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(docx), int64(len(docx)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		if _, dup := parts[f.Name]; dup {
+			t.Errorf("part %s written twice", f.Name)
+		}
+		b, err := readZipFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name] = string(b)
+	}
+	return parts
+}
+
+func TestDocxAnnotatorSplitRuns(t *testing.T) {
+	body := `<w:p><w:r><w:t>Ho</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>la, mundo</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>adiós</w:t></w:r></w:p>`
+	notes := []domain.TextNote{{Start: 0, End: 4, Color: "yellow", Author: "DocSim", Comment: "copiado"}}
+	out, err := NewDocxAnnotator().Annotate(testDocx(t, body, nil), NewNormalizer().Normalize("Hola, mundo\nadiós"), notes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := docxParts(t, out)
+	want := `<w:body><w:p><w:commentRangeStart w:id="0"/>` +
+		`<w:r><w:rPr><w:highlight w:val="yellow"/></w:rPr><w:t xml:space="preserve">Ho</w:t></w:r>` +
+		`<w:r><w:rPr><w:b/><w:highlight w:val="yellow"/></w:rPr><w:t xml:space="preserve">la</w:t></w:r>` +
+		`<w:commentRangeEnd w:id="0"/><w:r><w:commentReference w:id="0"/></w:r>` +
+		`<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">, mundo</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t xml:space="preserve">adiós</w:t></w:r></w:p></w:body>`
+	if got := parts["word/document.xml"]; !strings.Contains(got, want) {
+		t.Errorf("document.xml = %s\nwant it to contain %s", got, want)
+	}
+	if got := parts["word/comments.xml"]; !strings.Contains(got, `<w:comment w:id="0" w:author="DocSim"`) ||
+		!strings.Contains(got, "copiado") {
+		t.Errorf("comments.xml = %s", got)
+	}
+	if parts["word/media/image1.png"] != "not really a png" {
+		t.Error("untouched part was not copied")
+	}
+}
+
+func TestDocxAnnotatorExistingComments(t *testing.T) {
+	existing := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:comments xmlns:w="` + wordNS + `"><w:comment w:id="3" w:author="Ana"><w:p><w:r><w:t>revisar</w:t></w:r></w:p></w:comment></w:comments>`
+	rels := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId9" Type="` + commentsRelType + `" Target="comments.xml"/></Relationships>`
+	types := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Override PartName="/word/comments.xml" ContentType="` + commentsContentType + `"/></Types>`
+	body := `<w:p><w:commentRangeStart w:id="3"/><w:r><w:t>uno dos tres</w:t></w:r><w:commentRangeEnd w:id="3"/></w:p>`
+	notes := []domain.TextNote{
+		{Start: 4, End: 7, Color: "cyan", Author: "DocSim", Comment: "primera"},
+		{Start: 40, End: 50, Color: "cyan", Author: "DocSim", Comment: "fuera del texto"},
+	}
+	out, err := NewDocxAnnotator().Annotate(testDocx(t, body, map[string]string{
+		"word/comments.xml":            existing,
+		"word/_rels/document.xml.rels": rels,
+		"[Content_Types].xml":          types,
+	}), "uno dos tres", notes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := docxParts(t, out)
+	comments := parts["word/comments.xml"]
+	if !strings.Contains(comments, `<w:comment w:id="3" w:author="Ana">`) || !strings.Contains(comments, `<w:comment w:id="4" w:author="DocSim"`) {
+		t.Errorf("comments.xml = %s", comments)
+	}
+	if strings.Contains(comments, "fuera del texto") || strings.Count(comments, "<w:comments ") != 1 {
+		t.Errorf("comments.xml = %s", comments)
+	}
+	if !strings.Contains(parts["word/document.xml"], `<w:commentRangeStart w:id="4"/><w:r><w:rPr><w:highlight w:val="cyan"/></w:rPr><w:t xml:space="preserve">dos</w:t></w:r><w:commentRangeEnd w:id="4"/>`) {
+		t.Errorf("document.xml = %s", parts["word/document.xml"])
+	}
+	if parts["word/_rels/document.xml.rels"] != rels || parts["[Content_Types].xml"] != types {
+		t.Error("comments relationship or content type added twice")
+	}
+}
+
+func TestDocxAnnotatorGenerated(t *testing.T) {
+	text := "uno dos tres cuatro"
+	notes := []domain.TextNote{{Start: 4, End: 12, Color: "green", Author: "A&B", Comment: "a < b\nc"}}
+	out, err := NewDocxAnnotator().Annotate(nil, text, notes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := docxParts(t, out)
+	for _, c := range []struct{ part, want string }{
+		{"word/document.xml", `<w:highlight w:val="green"/></w:rPr><w:t xml:space="preserve">dos tres</w:t>`},
+		{"word/comments.xml", `w:author="A&amp;B"`},
+		{"word/comments.xml", `<w:p><w:r><w:t xml:space="preserve">a &lt; b</w:t></w:r></w:p><w:p><w:r><w:t xml:space="preserve">c</w:t></w:r></w:p>`},
+		{"word/_rels/document.xml.rels", `Type="` + commentsRelType + `" Target="comments.xml"`},
+		{"[Content_Types].xml", `<Override PartName="/word/comments.xml"`},
+		{"_rels/.rels", `Target="word/document.xml"`},
+	} {
+		if !strings.Contains(parts[c.part], c.want) {
+			t.Errorf("%s = %s\nwant it to contain %s", c.part, parts[c.part], c.want)
+		}
+	}
+	// the regenerated document reads back as the same text
+	var got strings.Builder
+	for _, m := range docxTextRe.FindAllStringSubmatch(parts["word/document.xml"], -1) {
+		got.WriteString(html.UnescapeString(m[1]))
+	}
+	if got.String() != text {
+		t.Errorf("text = %q, want %q", got.String(), text)
+	}
+}
+
+func TestWithHighlight(t *testing.T) {
+	const h = `<w:highlight w:val="yellow"/>`
+	cases := []struct{ name, rPr, want string }{
+		{"no properties", "", `<w:rPr>` + h + `</w:rPr>`},
+		{"appended", `<w:rPr><w:b/><w:sz w:val="24"/></w:rPr>`, `<w:rPr><w:b/><w:sz w:val="24"/>` + h + `</w:rPr>`},
+		{"before underline", `<w:rPr><w:b/><w:u w:val="single"/><w:lang w:val="es-ES"/></w:rPr>`, `<w:rPr><w:b/>` + h + `<w:u w:val="single"/><w:lang w:val="es-ES"/></w:rPr>`},
+		{"before lang", `<w:rPr><w:i/><w:lang w:val="es-ES"/></w:rPr>`, `<w:rPr><w:i/>` + h + `<w:lang w:val="es-ES"/></w:rPr>`},
+		{"replaces highlight", `<w:rPr><w:highlight w:val="red"/><w:vertAlign w:val="superscript"/></w:rPr>`, `<w:rPr>` + h + `<w:vertAlign w:val="superscript"/></w:rPr>`},
+		{"ignores prefixes", `<w:rPr><w:uCs/><w:b/></w:rPr>`, `<w:rPr><w:uCs/><w:b/>` + h + `</w:rPr>`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := withHighlight(c.rPr, "yellow"); got != c.want {
+				t.Errorf("withHighlight = %s, want %s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestIsWordRuneMatchesNormalize(t *testing.T) {
+	for _, r := range "aZ09áÉñÜçß€-_ K" {
+		kept := NewNormalizer().Normalize(string(r)) != ""
+		if IsWordRune(r) != kept {
+			t.Errorf("IsWordRune(%q) = %v, Normalize keeps it: %v", r, !kept, kept)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// waitDelay bounds how long Wait blocks on inherited pipes after a kill.
+const waitDelay = 5 * time.Second
+
+// commandContext builds an external tool invocation bound to ctx: it is
+// killed, together with its children, when ctx is cancelled or times out.
+func commandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	killProcessGroup(cmd)
+	cmd.WaitDelay = waitDelay
+	return cmd
+}
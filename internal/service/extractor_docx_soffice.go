@@ -1,48 +1,62 @@
 package service
 
 import (
-	"bytes"
-	"errors"
+	"context"
 	"log"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"detector_plagio/backend/internal/ports"
 )
 
-type DocxSofficeExtractor struct{}
-func NewDocxSofficeExtractor() ports.Extractor { return &DocxSofficeExtractor{} }
+type DocxSofficeExtractor struct {
+	pool    *SofficePool
+	daemon  *SofficeDaemonPool // optional; nil converts one-shot only
+	timeout time.Duration
+}
+func NewDocxSofficeExtractor(pool *SofficePool, daemon *SofficeDaemonPool, timeout time.Duration) ports.Extractor {
+	return &DocxSofficeExtractor{pool: pool, daemon: daemon, timeout: timeout}
+}
 func (e *DocxSofficeExtractor) CanHandle(ext string) bool {
 	ext = strings.ToLower(ext)
-	return ext == ".docx" || ext == ".txt"
+	return ext == ".docx" || ext == ".doc" || ext == ".txt"
 }
-func (e *DocxSofficeExtractor) Extract(inputPath string) (string, error) {
+func (e *DocxSofficeExtractor) Extensions() []string { return []string{".docx", ".doc", ".txt"} }
+func (e *DocxSofficeExtractor) Version() string { return "1" }
+func (e *DocxSofficeExtractor) Extract(ctx context.Context, inputPath string) (string, error) {
 	if strings.HasSuffix(strings.ToLower(inputPath), ".txt") {
-		b, err := os.ReadFile(inputPath); return string(b), err
+		b, err := os.ReadFile(inputPath); if err != nil { return "", err }
+		return decodeText(b, detectEncoding(b)), nil
 	}
-	tmp := os.TempDir()
-	cmd := exec.Command("soffice", "--headless", "--convert-to", "txt:Text", "--outdir", tmp, inputPath)
-	var errb bytes.Buffer
-	cmd.Stderr = &errb
-	if err := cmd.Run(); err != nil { return "", err }
-	outPath := filepath.Join(tmp, strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))+".txt")
-	defer os.Remove(outPath)
-	b, err := os.ReadFile(outPath)
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	b, err := e.convert(ctx, inputPath)
 	return string(b), err
 }
 
-func (e *DocxSofficeExtractor) ExtractFromBytes(data []byte, ext string) (string, error) {
-	log.Printf("ExtractFromBytes for docx called with data length: %d, ext: %s", len(data), ext)
+// convert prefers the persistent daemon and falls back to a one-shot soffice
+// run when it is unavailable or fails for reasons other than ctx ending.
+func (e *DocxSofficeExtractor) convert(ctx context.Context, inputPath string) ([]byte, error) {
+	if e.daemon != nil && e.daemon.Available() {
+		b, err := e.daemon.Convert(ctx, inputPath, "txt:Text")
+		if err == nil || ctx.Err() != nil {
+			return b, err
+		}
+		log.Printf("DocxSofficeExtractor: daemon conversion failed, falling back to one-shot: %v", err)
+	}
+	return e.pool.Convert(ctx, inputPath, "txt:Text")
+}
+
+func (e *DocxSofficeExtractor) ExtractFromBytes(ctx context.Context, data []byte, ext string) (string, error) {
+	log.Printf("ExtractFromBytes for soffice called with data length: %d, ext: %s", len(data), ext)
 	if strings.ToLower(ext) == ".txt" {
 		log.Println("ExtractFromBytes: handling .txt directly")
-		return string(data), nil
+		return decodeText(data, detectEncoding(data)), nil
 	}
 
-	tmpDir := os.TempDir()
 	// Create a temporary input file
-	inputFile, err := os.CreateTemp(tmpDir, "soffice_input_*.docx")
+	inputFile, err := os.CreateTemp("", "soffice_input_*"+strings.ToLower(ext))
 	if err != nil {
 		log.Printf("ExtractFromBytes: error creating temp file: %v", err)
 		return "", err
@@ -61,25 +75,19 @@ func (e *DocxSofficeExtractor) ExtractFromBytes(data []byte, ext string) (string
 		return "", err
 	}
 
-	// soffice conversion
-	cmd := exec.Command("soffice", "--headless", "--convert-to", "txt:Text", "--outdir", tmpDir, inputFile.Name())
-	var errb bytes.Buffer
-	cmd.Stderr = &errb
-	log.Printf("ExtractFromBytes: running soffice command: %s %v", cmd.Path, cmd.Args)
-	if err := cmd.Run(); err != nil {
-		log.Printf("ExtractFromBytes: soffice command failed: %v, stderr: %s", err, errb.String())
-		return "", errors.New(err.Error() + ": " + errb.String())
-	}
-
-	outputFileName := strings.TrimSuffix(filepath.Base(inputFile.Name()), filepath.Ext(inputFile.Name())) + ".txt"
-	outPath := filepath.Join(tmpDir, outputFileName)
-	defer os.Remove(outPath)
-
-	b, err := os.ReadFile(outPath)
+	// soffice conversion, bounded by the extractor timeout
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	b, err := e.convert(ctx, inputFile.Name())
 	if err != nil {
-		log.Printf("ExtractFromBytes: error reading output file: %v", err)
+		log.Printf("ExtractFromBytes: soffice conversion failed: %v", err)
 		return "", err
 	}
 	log.Printf("ExtractFromBytes: successfully extracted text with length %d", len(b))
 	return string(b), nil
 }
+
+func (e *DocxSofficeExtractor) ExtractMetadata(ctx context.Context, data []byte, ext string) (map[string]string, error) {
+	if strings.ToLower(ext) != ".docx" { return nil, nil }
+	return ooxmlMetadata(data)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"detector_plagio/backend/internal/ports"
+)
+
+// htmlSkipElements never contribute visible text.
+var htmlSkipElements = map[string]bool{"script": true, "style": true, "noscript": true, "template": true, "head": true}
+
+// htmlBlockElements end a line of text when they close.
+var htmlBlockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "blockquote": true, "pre": true, "section": true, "article": true,
+	"header": true, "footer": true, "table": true, "ul": true, "ol": true, "dt": true, "dd": true, "title": true,
+}
+
+var (
+	htmlRawTextRe = regexp.MustCompile(`(?is)<script[^>]*>.*?</script>|<style[^>]*>.*?</style>|<!--.*?-->`)
+	htmlTagRe     = regexp.MustCompile(`<[^>]*>`)
+)
+
+// HTMLExtractor walks the markup with the lenient HTML mode of encoding/xml.
+type HTMLExtractor struct{}
+
+func NewHTMLExtractor() ports.Extractor { return &HTMLExtractor{} }
+
+func (e *HTMLExtractor) CanHandle(ext string) bool {
+	ext = strings.ToLower(ext)
+	return ext == ".html" || ext == ".htm"
+}
+func (e *HTMLExtractor) Extensions() []string { return []string{".html", ".htm"} }
+func (e *HTMLExtractor) Version() string      { return "1" }
+
+func (e *HTMLExtractor) Extract(ctx context.Context, inputPath string) (string, error) {
+	b, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", err
+	}
+	return e.ExtractFromBytes(ctx, b, ".html")
+}
+
+func (e *HTMLExtractor) ExtractFromBytes(ctx context.Context, data []byte, ext string) (string, error) {
+	// script and style bodies are not markup and would trip the tokenizer
+	data = htmlRawTextRe.ReplaceAll(data, nil)
+	text, err := htmlText(data)
+	if err != nil {
+		// markup too broken for the tokenizer: fall back to stripping tags
+		text = html.UnescapeString(htmlTagRe.ReplaceAllString(string(data), " "))
+	}
+	return strings.TrimSpace(text), nil
+}
+
+func htmlText(data []byte) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+	var sb strings.Builder
+	skip := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if htmlSkipElements[name] {
+				skip++
+			}
+			if name == "br" {
+				sb.WriteByte('\n')
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if htmlSkipElements[name] && skip > 0 {
+				skip--
+			}
+			if htmlBlockElements[name] {
+				sb.WriteByte('\n')
+			} else if name == "td" || name == "th" {
+				sb.WriteByte('\t')
+			}
+		case xml.CharData:
+			if skip == 0 {
+				sb.Write(t)
+			}
+		}
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"html"
+	"os"
+	"regexp"
+	"strings"
+
+	"detector_plagio/backend/internal/ports"
+)
+
+var (
+	mdFenceRe    = regexp.MustCompile("^\\s*(```|~~~)")
+	mdHeadingRe  = regexp.MustCompile(`^\s{0,3}#{1,6}\s+`)
+	mdQuoteRe    = regexp.MustCompile(`^\s{0,3}(>\s?)+`)
+	mdListRe     = regexp.MustCompile(`^\s*([*+\-]|\d+[.)])\s+`)
+	mdRuleRe     = regexp.MustCompile(`^\s{0,3}([-*_]\s*){3,}$`)
+	mdSetextRe   = regexp.MustCompile(`^\s{0,3}(=+|-+)\s*$`)
+	mdRefDefRe   = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s+\S+`)
+	mdImageRe    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLinkRe     = regexp.MustCompile(`\[([^\]]*)\](\([^)]*\)|\[[^\]]*\])`)
+	mdAutoLinkRe = regexp.MustCompile(`<((?:https?|mailto):[^>]+)>`)
+	// emphasis needs the same delimiter on both sides; "_" only counts at
+	// word boundaries, so snake_case_name keeps its underscores
+	mdEmphasisRes = []*regexp.Regexp{
+		regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`),
+		regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`),
+		regexp.MustCompile(`\*(\S(?:.*?\S)?)\*`),
+	}
+	mdUnderscoreRes = []*regexp.Regexp{
+		regexp.MustCompile(`(^|[^\p{L}\p{N}_])__(\S(?:.*?\S)?)__($|[^\p{L}\p{N}_])`),
+		regexp.MustCompile(`(^|[^\p{L}\p{N}_])_(\S(?:.*?\S)?)_($|[^\p{L}\p{N}_])`),
+	}
+	mdCodeSpanRe  = regexp.MustCompile("`+([^`]*)`+")
+	mdInlineTagRe = regexp.MustCompile(`<[^>]+>`)
+	mdTableSepRe  = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+// MarkdownExtractor removes Markdown syntax and keeps the prose, including code blocks.
+type MarkdownExtractor struct{}
+
+func NewMarkdownExtractor() ports.Extractor { return &MarkdownExtractor{} }
+
+func (e *MarkdownExtractor) CanHandle(ext string) bool {
+	ext = strings.ToLower(ext)
+	return ext == ".md" || ext == ".markdown"
+}
+func (e *MarkdownExtractor) Extensions() []string { return []string{".md", ".markdown"} }
+func (e *MarkdownExtractor) Version() string      { return "2" }
+
+func (e *MarkdownExtractor) Extract(ctx context.Context, inputPath string) (string, error) {
+	b, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", err
+	}
+	return e.ExtractFromBytes(ctx, b, ".md")
+}
+
+func (e *MarkdownExtractor) ExtractFromBytes(ctx context.Context, data []byte, ext string) (string, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	out := make([]string, 0, len(lines))
+	inFence := false
+	for _, line := range lines {
+		if mdFenceRe.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			out = append(out, line)
+			continue
+		}
+		if mdRuleRe.MatchString(line) || mdSetextRe.MatchString(line) || mdRefDefRe.MatchString(line) || mdTableSepRe.MatchString(line) {
+			continue
+		}
+		if mdHeadingRe.MatchString(line) {
+			line = strings.TrimRight(mdHeadingRe.ReplaceAllString(line, ""), "# ")
+		}
+		line = mdQuoteRe.ReplaceAllString(line, "")
+		line = mdListRe.ReplaceAllString(line, "")
+		line = mdImageRe.ReplaceAllString(line, "$1")
+		line = mdLinkRe.ReplaceAllString(line, "$1")
+		line = mdAutoLinkRe.ReplaceAllString(line, "$1")
+		line = mdCodeSpanRe.ReplaceAllString(line, "$1")
+		line = stripEmphasis(line)
+		line = mdInlineTagRe.ReplaceAllString(line, "")
+		line = strings.ReplaceAll(line, "|", " ")
+		out = append(out, html.UnescapeString(line))
+	}
+	return strings.TrimSpace(strings.Join(out, "\n")), nil
+}
+
+func stripEmphasis(line string) string {
+	for _, re := range mdEmphasisRes {
+		line = re.ReplaceAllString(line, "$1")
+	}
+	for _, re := range mdUnderscoreRes {
+		// a match consumes the boundary after it, which may open the next one
+		for next := re.ReplaceAllString(line, "$1$2$3"); next != line; next = re.ReplaceAllString(line, "$1$2$3") {
+			line = next
+		}
+	}
+	return line
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMarkdownExtract(t *testing.T) {
+	cases := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{"strong and emphasis", "Un **texto** con *énfasis* y ~~tachado~~.", "Un texto con énfasis y tachado."},
+		{"underscore emphasis", "Es _importante_ y __muy__ _claro_.", "Es importante y muy claro."},
+		{"adjacent underscores", "_uno_ _dos_", "uno dos"},
+		{"snake case", "La variable snake_case_name y __init__ quedan.", "La variable snake_case_name y init quedan."},
+		{"unbalanced strong", "a **b* c", "a *b c"},
+		{"mixed delimiters", "*uno_ y _dos_", "*uno_ y dos"},
+		{"underscore inside star", "*uno_ y dos*", "uno_ y dos"},
+		{"math stars", "2 * 3 * 4", "2 * 3 * 4"},
+		{"heading and list", "# Título #\n- item con [enlace](http://x)\n", "Título\nitem con enlace"},
+		{"code kept", "```\nx_y = a*b\n```\n`code_span`", "x_y = a*b\ncode_span"},
+	}
+	e := NewMarkdownExtractor()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := e.ExtractFromBytes(context.Background(), []byte(c.md), ".md")
+			if err != nil || got != c.want {
+				t.Errorf("Extract = %q, %v; want %q", got, err, c.want)
+			}
+		})
+	}
+}
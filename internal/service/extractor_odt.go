@@ -0,0 +1,102 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"detector_plagio/backend/internal/ports"
+)
+
+const odfTextNS = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+
+// ODTExtractor reads content.xml from an OpenDocument text package without LibreOffice.
+type ODTExtractor struct{}
+
+func NewODTExtractor() ports.Extractor { return &ODTExtractor{} }
+
+func (e *ODTExtractor) CanHandle(ext string) bool { return strings.EqualFold(ext, ".odt") }
+func (e *ODTExtractor) Extensions() []string      { return []string{".odt"} }
+func (e *ODTExtractor) Version() string           { return "1" }
+
+func (e *ODTExtractor) Extract(ctx context.Context, inputPath string) (string, error) {
+	b, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", err
+	}
+	return e.ExtractFromBytes(ctx, b, ".odt")
+}
+
+func (e *ODTExtractor) ExtractFromBytes(ctx context.Context, data []byte, ext string) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+	for _, f := range zr.File {
+		if f.Name != "content.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+		defer rc.Close()
+		return odfText(rc)
+	}
+	return "", errors.New("odt: content.xml not found")
+}
+
+func (e *ODTExtractor) ExtractMetadata(ctx context.Context, data []byte, ext string) (map[string]string, error) {
+	return odfMetadata(data)
+}
+
+// odfText flattens the text:* elements of an ODF body into plain text,
+// expanding the spacing elements ODF uses instead of literal whitespace.
+func odfText(r io.Reader) (string, error) {
+	dec := xml.NewDecoder(r)
+	var sb strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space != odfTextNS {
+				continue
+			}
+			switch t.Name.Local {
+			case "s":
+				n := 1
+				for _, a := range t.Attr {
+					if a.Name.Local == "c" {
+						if c, err := strconv.Atoi(a.Value); err == nil && c > 0 {
+							n = c
+						}
+					}
+				}
+				sb.WriteString(strings.Repeat(" ", n))
+			case "tab":
+				sb.WriteByte('\t')
+			case "line-break":
+				sb.WriteByte('\n')
+			}
+		case xml.EndElement:
+			if t.Name.Space == odfTextNS && (t.Name.Local == "p" || t.Name.Local == "h") {
+				sb.WriteByte('\n')
+			}
+		case xml.CharData:
+			sb.Write(t)
+		}
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
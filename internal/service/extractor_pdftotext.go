@@ -2,35 +2,60 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"log"
-	"os/exec"
 	"strings"
+	"time"
+	"unicode"
 
 	"detector_plagio/backend/internal/ports"
 )
 
-type PDFToTextExtractor struct{}
-func NewPDFToTextExtractor() ports.Extractor { return &PDFToTextExtractor{} }
+type PDFToTextExtractor struct{ timeout time.Duration }
+func NewPDFToTextExtractor(timeout time.Duration) ports.Extractor { return &PDFToTextExtractor{timeout: timeout} }
 func (e *PDFToTextExtractor) CanHandle(ext string) bool { return strings.EqualFold(ext, ".pdf") }
-func (e *PDFToTextExtractor) Extract(inputPath string) (string, error) {
-	cmd := exec.Command("pdftotext", "-layout", inputPath, "-")
+func (e *PDFToTextExtractor) Extensions() []string { return []string{".pdf"} }
+func (e *PDFToTextExtractor) Version() string { return "1" }
+func (e *PDFToTextExtractor) Extract(ctx context.Context, inputPath string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	cmd := commandContext(ctx, "pdftotext", "-layout", inputPath, "-")
 	var out, errb bytes.Buffer
 	cmd.Stdout = &out; cmd.Stderr = &errb
-	if err := cmd.Run(); err != nil { return "", err }
-	return strings.TrimSpace(out.String()), nil
+	if err := cmd.Run(); err != nil { return "", pdftotextError(ctx, err, errb.String()) }
+	return pdfPages(out.String()), nil
 }
 
-func (e *PDFToTextExtractor) ExtractFromBytes(data []byte, ext string) (string, error) {
+func (e *PDFToTextExtractor) ExtractFromBytes(ctx context.Context, data []byte, ext string) (string, error) {
 	log.Printf("ExtractFromBytes for pdf called with data length: %d, ext: %s", len(data), ext)
-	cmd := exec.Command("pdftotext", "-layout", "-", "-")
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	cmd := commandContext(ctx, "pdftotext", "-layout", "-", "-")
 	cmd.Stdin = bytes.NewReader(data)
 	var out, errb bytes.Buffer
 	cmd.Stdout = &out; cmd.Stderr = &errb
 	log.Printf("ExtractFromBytes: running pdftotext command: %s %v", cmd.Path, cmd.Args)
 	if err := cmd.Run(); err != nil {
 		log.Printf("ExtractFromBytes: pdftotext command failed: %v, stderr: %s", err, errb.String())
-		return "", err
+		return "", pdftotextError(ctx, err, errb.String())
 	}
 	log.Printf("ExtractFromBytes: successfully extracted text with length %d", len(out.String()))
-	return strings.TrimSpace(out.String()), nil
+	return pdfPages(out.String()), nil
+}
+
+func (e *PDFToTextExtractor) ExtractMetadata(ctx context.Context, data []byte, ext string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	return pdfMetadata(ctx, data)
+}
+
+// pdfPages trims only the end of the output: the form feeds before the text
+// of a PDF whose first pages are blank scans keep page i of the text at page i.
+func pdfPages(out string) string { return strings.TrimRightFunc(out, unicode.IsSpace) }
+
+func pdftotextError(ctx context.Context, err error, stderr string) error {
+	if ctx.Err() != nil {
+		return &ports.ToolError{Tool: "pdftotext", Err: ctx.Err()}
+	}
+	return &ports.ToolError{Tool: "pdftotext", Err: err, Stderr: strings.TrimSpace(stderr)}
 }
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"log"
+	"sort"
+	"strings"
+
+	"detector_plagio/backend/internal/ports"
+)
+
+type ExtractorRegistry struct {
+	byExt map[string]ports.Extractor
+}
+
+// NewExtractorRegistry indexes the extractors by the extensions they declare.
+// When two extractors claim the same extension the first one wins.
+func NewExtractorRegistry(exs ...ports.Extractor) *ExtractorRegistry {
+	r := &ExtractorRegistry{byExt: map[string]ports.Extractor{}}
+	for _, e := range exs {
+		for _, ext := range e.Extensions() {
+			ext = strings.ToLower(ext)
+			if _, taken := r.byExt[ext]; taken {
+				log.Printf("ExtractorRegistry: %s already registered, ignoring %T", ext, e)
+				continue
+			}
+			r.byExt[ext] = e
+		}
+	}
+	return r
+}
+
+func (r *ExtractorRegistry) For(ext string) ports.Extractor {
+	return r.byExt[strings.ToLower(ext)]
+}
+
+func (r *ExtractorRegistry) Extensions() []string {
+	out := make([]string, 0, len(r.byExt))
+	for ext := range r.byExt {
+		out = append(out, ext)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// AllowedExtMap returns the registered extensions in the shape config.Config expects.
+func (r *ExtractorRegistry) AllowedExtMap() map[string]bool {
+	m := make(map[string]bool, len(r.byExt))
+	for ext := range r.byExt {
+		m[ext] = true
+	}
+	return m
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+
+	"detector_plagio/backend/internal/ports"
+)
+
+// rtfSkipDestinations are groups that carry formatting or metadata, not body text.
+var rtfSkipDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true, "pict": true,
+	"themedata": true, "colorschememapping": true, "datastore": true, "latentstyles": true,
+	"listtable": true, "listoverridetable": true, "rsidtbl": true, "generator": true,
+	"xmlnstbl": true, "mmathPr": true, "filetbl": true, "revtbl": true, "object": true,
+	"header": true, "footer": true, "headerl": true, "headerr": true, "headerf": true,
+	"footerl": true, "footerr": true, "footerf": true, "fldinst": true,
+}
+
+// RTFExtractor strips RTF control words and groups, keeping the body text.
+type RTFExtractor struct{}
+
+func NewRTFExtractor() ports.Extractor { return &RTFExtractor{} }
+
+func (e *RTFExtractor) CanHandle(ext string) bool { return strings.EqualFold(ext, ".rtf") }
+func (e *RTFExtractor) Extensions() []string      { return []string{".rtf"} }
+func (e *RTFExtractor) Version() string           { return "1" }
+
+func (e *RTFExtractor) Extract(ctx context.Context, inputPath string) (string, error) {
+	b, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", err
+	}
+	return e.ExtractFromBytes(ctx, b, ".rtf")
+}
+
+func (e *RTFExtractor) ExtractFromBytes(ctx context.Context, data []byte, ext string) (string, error) {
+	if !strings.HasPrefix(string(data[:min(len(data), 5)]), `{\rtf`) {
+		return "", errors.New("rtf: missing {\\rtf header")
+	}
+	return rtfText(data), nil
+}
+
+func (e *RTFExtractor) ExtractMetadata(ctx context.Context, data []byte, ext string) (map[string]string, error) {
+	return rtfMetadata(data), nil
+}
+
+type rtfState struct {
+	skip bool
+	uc   int // characters to drop after a \uN escape
+}
+
+func rtfText(data []byte) string {
+	var sb strings.Builder
+	stack := []rtfState{{uc: 1}}
+	skipChars := 0
+	for i := 0; i < len(data); i++ {
+		st := &stack[len(stack)-1]
+		c := data[i]
+		switch c {
+		case '{':
+			stack = append(stack, *st)
+			continue
+		case '}':
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		case '\r', '\n':
+			continue
+		case '\\':
+		default:
+			if skipChars > 0 {
+				skipChars--
+				continue
+			}
+			if !st.skip {
+				sb.WriteByte(c)
+			}
+			continue
+		}
+
+		// control symbol or control word
+		i++
+		if i >= len(data) {
+			break
+		}
+		c = data[i]
+		switch {
+		case c == '\\' || c == '{' || c == '}':
+			if !st.skip {
+				sb.WriteByte(c)
+			}
+		case c == '*':
+			st.skip = true
+		case c == '~':
+			if !st.skip {
+				sb.WriteByte(' ')
+			}
+		case c == '-' || c == '_':
+			if !st.skip && c == '_' {
+				sb.WriteByte('-')
+			}
+		case c == '\'':
+			if i+2 < len(data) {
+				if v, err := strconv.ParseUint(string(data[i+1:i+3]), 16, 8); err == nil {
+					if skipChars > 0 {
+						skipChars--
+					} else if !st.skip {
+						sb.WriteRune(decodeByte(byte(v), true))
+					}
+				}
+				i += 2
+			}
+		case c == '\r' || c == '\n':
+			if !st.skip {
+				sb.WriteByte('\n')
+			}
+		case isASCIILetter(c):
+			start := i
+			for i < len(data) && isASCIILetter(data[i]) {
+				i++
+			}
+			word := string(data[start:i])
+			numStart := i
+			if i < len(data) && data[i] == '-' {
+				i++
+			}
+			for i < len(data) && data[i] >= '0' && data[i] <= '9' {
+				i++
+			}
+			param, hasParam := 0, i > numStart
+			if hasParam {
+				param, _ = strconv.Atoi(string(data[numStart:i]))
+			}
+			// a single space delimits the control word and is not text
+			if i >= len(data) || data[i] != ' ' {
+				i--
+			}
+			if rtfSkipDestinations[word] {
+				st.skip = true
+				continue
+			}
+			if st.skip {
+				continue
+			}
+			switch word {
+			case "par", "line", "sect", "page", "row":
+				sb.WriteByte('\n')
+			case "tab", "cell":
+				sb.WriteByte('\t')
+			case "emdash":
+				sb.WriteString("—")
+			case "endash":
+				sb.WriteString("–")
+			case "lquote", "rquote":
+				sb.WriteByte('\'')
+			case "ldblquote", "rdblquote":
+				sb.WriteByte('"')
+			case "bullet":
+				sb.WriteString("•")
+			case "uc":
+				if hasParam {
+					st.uc = param
+				}
+			case "u":
+				if param < 0 {
+					param += 65536
+				}
+				sb.WriteRune(rune(param))
+				skipChars = st.uc
+			}
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func isASCIILetter(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRTFText(t *testing.T) {
+	cases := []struct {
+		name string
+		rtf  string
+		want string
+	}{
+		{"plain", `{\rtf1\ansi Hola mundo}`, "Hola mundo"},
+		{"hex escapes cp1252", `{\rtf1\ansi Canci\'f3n del ni\'f1o}`, "Canción del niño"},
+		{"hex smart quotes", `{\rtf1\ansi \'93cita\'94 \'97 fin}`, "“cita” — fin"},
+		{"uppercase hex", `{\rtf1\ansi a\'E9b}`, "aéb"},
+		{"unicode with fallback", `{\rtf1\ansi\uc1 ni\u241?o}`, "niño"},
+		{"unicode with hex fallback", `{\rtf1\ansi\uc1 ni\u241\'f1o}`, "niño"},
+		{"negative unicode", `{\rtf1\ansi \u-4064?}`, "\uf020"},
+		{"paragraphs and tabs", `{\rtf1\ansi uno\par dos\tab tres}`, "uno\ndos\ttres"},
+		{"escaped braces", `{\rtf1\ansi a\{b\}c\\d}`, `a{b}c\d`},
+		{"skipped destinations", `{\rtf1\ansi{\fonttbl{\f0 Arial;}}{\*\generator Word;}{\info{\author Ana}}Texto}`, "Texto"},
+		{"field instruction", `{\rtf1\ansi {\field{\*\fldinst HYPERLINK "x"}{\fldrslt enlace}}}`, "enlace"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := rtfText([]byte(c.rtf)); got != c.want {
+				t.Errorf("rtfText = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestRTFRejectsMissingHeader(t *testing.T) {
+	if _, err := NewRTFExtractor().ExtractFromBytes(context.Background(), []byte("plain text"), ".rtf"); err == nil {
+		t.Error("expected an error for a file without the {\\rtf header")
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package service
+
+import "os"
+
+// lockIndexDir only creates the lock file; without flock two processes
+// sharing an index are not detected.
+func lockIndexDir(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package service
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockIndexDir takes an exclusive lock on path for as long as the returned
+// file stays open. The kernel drops it when the process dies, so a crash
+// never leaves a stale lock behind.
+func lockIndexDir(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, ErrIndexLocked
+		}
+		return nil, err
+	}
+	return f, nil
+}
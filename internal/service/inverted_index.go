@@ -0,0 +1,407 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"unicode"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+// indexFormat is bumped when the snapshot layout or tokenization changes;
+// an older snapshot is ignored and rebuilt from the documents.
+const indexFormat = 1
+
+// compactMinBytes is the journal size below which it is not worth folding
+// into a new snapshot outside of Flush.
+const compactMinBytes = 16 << 20
+
+var errIndexClosed = errors.New("index is closed")
+
+// ErrIndexLocked is returned by NewInvertedIndex while another process, such
+// as a running server, holds the index directory.
+var ErrIndexLocked = errors.New("index is in use by another process")
+
+// foldRunes removes the diacritics Spanish and Portuguese texts use, so that
+// "educacion" finds "educación".
+var foldRunes = map[rune]rune{
+	'á': 'a', 'à': 'a', 'ä': 'a', 'â': 'a', 'ã': 'a',
+	'é': 'e', 'è': 'e', 'ë': 'e', 'ê': 'e',
+	'í': 'i', 'ì': 'i', 'ï': 'i', 'î': 'i',
+	'ó': 'o', 'ò': 'o', 'ö': 'o', 'ô': 'o', 'õ': 'o',
+	'ú': 'u', 'ù': 'u', 'ü': 'u', 'û': 'u',
+	'ñ': 'n', 'ç': 'c',
+}
+
+type indexedDoc struct {
+	Folder string
+	Hash   string
+	Terms  []string
+}
+
+type indexSnapshot struct {
+	Format   int
+	Postings map[string]map[string][]int32
+	Docs     map[string]indexedDoc
+}
+
+// journalEntry is one change appended to the journal: a document indexed
+// with its term positions, or removed.
+type journalEntry struct {
+	Format    int
+	ID        string
+	Deleted   bool
+	Doc       indexedDoc
+	Positions map[string][]int32
+}
+
+// InvertedIndex keeps term -> document -> positions in memory. Every change
+// is appended to a journal as it happens; the journal is folded into a gob
+// snapshot on Flush and whenever it outgrows the snapshot, so a change costs
+// one record rather than a rewrite of the whole corpus.
+type InvertedIndex struct {
+	path        string
+	journalPath string
+
+	mu       sync.RWMutex
+	postings map[string]map[string][]int32
+	docs     map[string]indexedDoc
+	journal  *os.File
+	lock     *os.File
+	closed   bool
+	// the sizes also change under a read lock in Flush, which excludes
+	// writers but not other readers
+	journalSize  atomic.Int64
+	snapshotSize atomic.Int64
+
+	flushMu    sync.Mutex
+	compacting atomic.Bool
+}
+
+func NewInvertedIndex(dir string) (ports.TextIndex, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	idx := &InvertedIndex{
+		path:        filepath.Join(dir, "inverted.gob"),
+		journalPath: filepath.Join(dir, "inverted.journal"),
+		postings:    map[string]map[string][]int32{},
+		docs:        map[string]indexedDoc{},
+	}
+	// both the snapshot and the journal are rewritten in place; a second
+	// process writing them would drop the first one's changes
+	lock, err := lockIndexDir(filepath.Join(dir, "LOCK"))
+	if err != nil {
+		return nil, err
+	}
+	if err := idx.loadSnapshot(); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	if err := idx.replayJournal(); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	f, err := os.OpenFile(idx.journalPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		lock.Close()
+		return nil, err
+	}
+	idx.journal, idx.lock = f, lock
+	log.Printf("InvertedIndex: loaded %d documents, %d terms", len(idx.docs), len(idx.postings))
+	return idx, nil
+}
+
+func (x *InvertedIndex) loadSnapshot() error {
+	f, err := os.Open(x.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var snap indexSnapshot
+	if err := gob.NewDecoder(f).Decode(&snap); err != nil || snap.Format != indexFormat {
+		log.Printf("InvertedIndex: ignoring snapshot %s (format %d, err %v)", x.path, snap.Format, err)
+		return nil
+	}
+	x.postings, x.docs = snap.Postings, snap.Docs
+	if info, err := f.Stat(); err == nil {
+		x.snapshotSize.Store(info.Size())
+	}
+	return nil
+}
+
+// replayJournal applies the changes made since the snapshot. A record cut
+// short by a crash ends the replay and is cut off the file.
+func (x *InvertedIndex) replayJournal() error {
+	b, err := os.ReadFile(x.journalPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	off := 0
+	for off+4 <= len(b) {
+		n := int(binary.LittleEndian.Uint32(b[off:]))
+		if off+4+n > len(b) {
+			break
+		}
+		var e journalEntry
+		if err := gob.NewDecoder(bytes.NewReader(b[off+4 : off+4+n])).Decode(&e); err != nil || e.Format != indexFormat {
+			break
+		}
+		if e.Deleted {
+			x.removeLocked(e.ID)
+		} else {
+			x.applyLocked(e.ID, e.Doc, e.Positions)
+		}
+		off += 4 + n
+	}
+	if off < len(b) {
+		log.Printf("InvertedIndex: dropping %d bytes of unreadable journal", len(b)-off)
+		if err := os.Truncate(x.journalPath, int64(off)); err != nil {
+			return err
+		}
+	}
+	x.journalSize.Store(int64(off))
+	return nil
+}
+
+func (x *InvertedIndex) Put(doc domain.Document) error {
+	toks := x.Tokens(doc.TextContent)
+	positions := map[string][]int32{}
+	for i, t := range toks {
+		positions[t.Term] = append(positions[t.Term], int32(i))
+	}
+	terms := make([]string, 0, len(positions))
+	for t := range positions {
+		terms = append(terms, t)
+	}
+
+	d := indexedDoc{Folder: doc.Folder, Hash: textHash(doc.TextContent), Terms: terms}
+
+	x.mu.Lock()
+	if x.closed {
+		x.mu.Unlock()
+		return errIndexClosed
+	}
+	x.applyLocked(doc.ID, d, positions)
+	err := x.appendLocked(journalEntry{ID: doc.ID, Doc: d, Positions: positions})
+	x.mu.Unlock()
+	x.maybeCompact()
+	return err
+}
+
+func (x *InvertedIndex) Delete(id string) error {
+	x.mu.Lock()
+	if x.closed {
+		x.mu.Unlock()
+		return errIndexClosed
+	}
+	x.removeLocked(id)
+	err := x.appendLocked(journalEntry{ID: id, Deleted: true})
+	x.mu.Unlock()
+	x.maybeCompact()
+	return err
+}
+
+func (x *InvertedIndex) applyLocked(id string, d indexedDoc, positions map[string][]int32) {
+	x.removeLocked(id)
+	for t, pos := range positions {
+		m, ok := x.postings[t]
+		if !ok {
+			m = map[string][]int32{}
+			x.postings[t] = m
+		}
+		m[id] = pos
+	}
+	x.docs[id] = d
+}
+
+func (x *InvertedIndex) removeLocked(id string) {
+	d, ok := x.docs[id]
+	if !ok {
+		return
+	}
+	for _, t := range d.Terms {
+		if m := x.postings[t]; m != nil {
+			delete(m, id)
+			if len(m) == 0 {
+				delete(x.postings, t)
+			}
+		}
+	}
+	delete(x.docs, id)
+}
+
+func (x *InvertedIndex) Postings(term string) map[string][]int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	out := make(map[string][]int, len(x.postings[term]))
+	for id, pos := range x.postings[term] {
+		p := make([]int, len(pos))
+		for i, v := range pos {
+			p[i] = int(v)
+		}
+		out[id] = p
+	}
+	return out
+}
+
+// Tokens lowercases and folds text and splits it on anything that is not a
+// letter or digit.
+func (x *InvertedIndex) Tokens(text string) []ports.Token {
+	var out []ports.Token
+	var term []rune
+	start, i := 0, 0
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if len(term) == 0 {
+				start = i
+			}
+			r = unicode.ToLower(r)
+			if f, ok := foldRunes[r]; ok {
+				r = f
+			}
+			term = append(term, r)
+		} else if len(term) > 0 {
+			out = append(out, ports.Token{Term: string(term), Start: start, End: i})
+			term = term[:0]
+		}
+		i++
+	}
+	if len(term) > 0 {
+		out = append(out, ports.Token{Term: string(term), Start: start, End: i})
+	}
+	return out
+}
+
+func (x *InvertedIndex) Folder(id string) (string, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	d, ok := x.docs[id]
+	return d.Folder, ok
+}
+
+func (x *InvertedIndex) IDs() []string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	out := make([]string, 0, len(x.docs))
+	for id := range x.docs {
+		out = append(out, id)
+	}
+	return out
+}
+
+func (x *InvertedIndex) Current(doc domain.Document) bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	d, ok := x.docs[doc.ID]
+	return ok && d.Folder == doc.Folder && d.Hash == textHash(doc.TextContent)
+}
+
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// appendLocked writes e to the journal as a length-prefixed gob record; a
+// failed write is cut off again so that later records stay readable.
+func (x *InvertedIndex) appendLocked(e journalEntry) error {
+	e.Format = indexFormat
+	var buf bytes.Buffer
+	buf.Write([]byte{0, 0, 0, 0})
+	if err := gob.NewEncoder(&buf).Encode(e); err != nil {
+		return err
+	}
+	b := buf.Bytes()
+	binary.LittleEndian.PutUint32(b, uint32(len(b)-4))
+	if _, err := x.journal.Write(b); err != nil {
+		x.journal.Truncate(x.journalSize.Load())
+		return err
+	}
+	x.journalSize.Add(int64(len(b)))
+	return nil
+}
+
+// maybeCompact folds the journal into the snapshot in the background once
+// replaying it would cost more than loading the snapshot.
+func (x *InvertedIndex) maybeCompact() {
+	due := x.journalSize.Load() > max(x.snapshotSize.Load(), compactMinBytes)
+	if due && x.compacting.CompareAndSwap(false, true) {
+		go func() {
+			defer x.compacting.Store(false)
+			if err := x.Flush(); err != nil {
+				log.Printf("InvertedIndex: compacting: %v", err)
+			}
+		}()
+	}
+}
+
+// Flush writes a new snapshot and empties the journal. Writers wait while
+// the snapshot is encoded; readers do not.
+func (x *InvertedIndex) Flush() error {
+	x.flushMu.Lock()
+	defer x.flushMu.Unlock()
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if x.closed || x.journalSize.Load() == 0 {
+		return nil
+	}
+	tmp := x.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = gob.NewEncoder(f).Encode(indexSnapshot{Format: indexFormat, Postings: x.postings, Docs: x.docs})
+	var info os.FileInfo
+	if err == nil {
+		info, err = f.Stat()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, x.path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	// replaying the old journal over the new snapshot would be harmless, so
+	// a crash before the truncate loses nothing
+	x.snapshotSize.Store(info.Size())
+	if err := x.journal.Truncate(0); err != nil {
+		return err
+	}
+	x.journalSize.Store(0)
+	return nil
+}
+
+func (x *InvertedIndex) Close() error {
+	err := x.Flush()
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.closed {
+		return err
+	}
+	x.closed = true
+	if cerr := x.journal.Close(); err == nil {
+		err = cerr
+	}
+	x.lock.Close()
+	return err
+}
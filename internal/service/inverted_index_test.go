@@ -0,0 +1,135 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+func openIndex(t *testing.T, dir string) ports.TextIndex {
+	t.Helper()
+	idx, err := NewInvertedIndex(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return idx
+}
+
+// crash drops idx without flushing, leaving its journal as a killed
+// process would.
+func crash(idx ports.TextIndex) {
+	x := idx.(*InvertedIndex)
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.closed = true
+	x.journal.Close()
+	x.lock.Close()
+}
+
+func journalSize(t *testing.T, dir string) int64 {
+	t.Helper()
+	info, err := os.Stat(filepath.Join(dir, "inverted.journal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+func TestInvertedIndexPersistence(t *testing.T) {
+	dir := t.TempDir()
+	idx := openIndex(t, dir)
+	docs := []domain.Document{
+		{ID: "a", Folder: "f1", TextContent: "la educación pública"},
+		{ID: "b", Folder: "f2", TextContent: "educacion y salud"},
+		{ID: "c", Folder: "f1", TextContent: "otro texto"},
+	}
+	for _, d := range docs {
+		if err := idx.Put(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := idx.Delete("c"); err != nil {
+		t.Fatal(err)
+	}
+	docs[0].TextContent = "educación pública y gratuita"
+	if err := idx.Put(docs[0]); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]int{"a": {0}, "b": {0}}
+
+	// every change is in the journal without a flush, as after a crash
+	crash(idx)
+	reopened := openIndex(t, dir)
+	if got := reopened.Postings("educacion"); !reflect.DeepEqual(got, want) {
+		t.Errorf("after replay Postings = %v, want %v", got, want)
+	}
+	if _, ok := reopened.Folder("c"); ok || !reopened.Current(docs[0]) {
+		t.Errorf("after replay: c still indexed or a not current")
+	}
+
+	if err := reopened.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := journalSize(t, dir); n != 0 {
+		t.Errorf("journal holds %d bytes after Close, want 0", n)
+	}
+	if err := reopened.Put(docs[2]); err == nil {
+		t.Error("Put after Close succeeded")
+	}
+	fromSnapshot := openIndex(t, dir)
+	defer fromSnapshot.Close()
+	if got := fromSnapshot.Postings("educacion"); !reflect.DeepEqual(got, want) {
+		t.Errorf("from snapshot Postings = %v, want %v", got, want)
+	}
+}
+
+func TestInvertedIndexLocked(t *testing.T) {
+	dir := t.TempDir()
+	idx := openIndex(t, dir)
+	if _, err := NewInvertedIndex(dir); !errors.Is(err, ErrIndexLocked) {
+		t.Fatalf("second open: err = %v, want ErrIndexLocked", err)
+	}
+	if err := idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+	openIndex(t, dir).Close()
+}
+
+func TestInvertedIndexTornJournal(t *testing.T) {
+	dir := t.TempDir()
+	idx := openIndex(t, dir)
+	if err := idx.Put(domain.Document{ID: "a", TextContent: "uno dos"}); err != nil {
+		t.Fatal(err)
+	}
+	good := journalSize(t, dir)
+	if err := idx.Put(domain.Document{ID: "b", TextContent: "dos tres"}); err != nil {
+		t.Fatal(err)
+	}
+	// cut the last record in half, as a crash mid-write would
+	if err := os.Truncate(filepath.Join(dir, "inverted.journal"), journalSize(t, dir)-3); err != nil {
+		t.Fatal(err)
+	}
+
+	crash(idx)
+	reopened := openIndex(t, dir)
+	if got, want := reopened.IDs(), []string{"a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("IDs = %v, want %v", got, want)
+	}
+	if n := journalSize(t, dir); n != good {
+		t.Errorf("journal is %d bytes, want the torn record cut back to %d", n, good)
+	}
+	if err := reopened.Put(domain.Document{ID: "c", TextContent: "cuatro"}); err != nil {
+		t.Fatal(err)
+	}
+	crash(reopened)
+	again := openIndex(t, dir)
+	defer again.Close()
+	if _, ok := again.Folder("c"); !ok {
+		t.Error("record appended after the torn one was lost")
+	}
+}
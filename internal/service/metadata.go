@@ -0,0 +1,243 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"detector_plagio/backend/internal/domain"
+)
+
+// ooxmlCoreProps maps docProps/core.xml and docProps/app.xml elements to metadata keys.
+var ooxmlCoreProps = map[string]string{
+	"creator":        domain.MetaAuthor,
+	"lastModifiedBy": domain.MetaLastModifiedBy,
+	"created":        domain.MetaCreated,
+	"modified":       domain.MetaModified,
+	"revision":       domain.MetaRevision,
+	"title":          domain.MetaTitle,
+	"Application":    domain.MetaApplication,
+	"Company":        domain.MetaCompany,
+	"Template":       domain.MetaTemplate,
+	"Pages":          domain.MetaPages,
+}
+
+// odfMetaProps maps meta.xml elements to metadata keys.
+var odfMetaProps = map[string]string{
+	"initial-creator": domain.MetaAuthor,
+	"creator":         domain.MetaLastModifiedBy,
+	"creation-date":   domain.MetaCreated,
+	"date":            domain.MetaModified,
+	"generator":       domain.MetaApplication,
+	"editing-cycles":  domain.MetaRevision,
+	"title":           domain.MetaTitle,
+	"page-count":      domain.MetaPages,
+}
+
+// pdfInfoProps maps pdfinfo output fields to metadata keys.
+var pdfInfoProps = map[string]string{
+	"Author":       domain.MetaAuthor,
+	"CreationDate": domain.MetaCreated,
+	"ModDate":      domain.MetaModified,
+	"Creator":      domain.MetaApplication,
+	"Producer":     domain.MetaProducer,
+	"Title":        domain.MetaTitle,
+	"Pages":        domain.MetaPages,
+}
+
+// rtfInfoRe captures the plain-text fields of an RTF \info group.
+var rtfInfoRe = regexp.MustCompile(`\{\\(author|operator|title|company)\s+([^{}]*)\}`)
+
+var rtfInfoProps = map[string]string{
+	"author":   domain.MetaAuthor,
+	"operator": domain.MetaLastModifiedBy,
+	"title":    domain.MetaTitle,
+	"company":  domain.MetaCompany,
+}
+
+// ooxmlMetadata reads core and app properties plus the document GUIDs Word
+// keeps in settings.xml: w15:docId survives "save as" and w:rsidRoot names
+// the editing session that created the file, so both link copies of a file.
+func ooxmlMetadata(data []byte) (map[string]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	meta := map[string]string{}
+	for _, f := range zr.File {
+		switch f.Name {
+		case "docProps/core.xml", "docProps/app.xml":
+			if err := readXMLProps(f, ooxmlCoreProps, meta); err != nil {
+				return meta, err
+			}
+		case "word/settings.xml":
+			if err := readWordSettings(f, meta); err != nil {
+				return meta, err
+			}
+		}
+	}
+	return meta, nil
+}
+
+// odfMetadata reads meta.xml of an OpenDocument package.
+func odfMetadata(data []byte) (map[string]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	meta := map[string]string{}
+	for _, f := range zr.File {
+		if f.Name == "meta.xml" {
+			err = readXMLProps(f, odfMetaProps, meta)
+			if err == nil {
+				err = readODFTemplate(f, meta)
+			}
+			return meta, err
+		}
+	}
+	return meta, nil
+}
+
+// readXMLProps copies the text of every element whose local name is in props.
+func readXMLProps(f *zip.File, props map[string]string, meta map[string]string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	dec := xml.NewDecoder(rc)
+	var key string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			key = props[t.Name.Local]
+		case xml.CharData:
+			if v := strings.TrimSpace(string(t)); key != "" && v != "" {
+				meta[key] = v
+			}
+		case xml.EndElement:
+			key = ""
+		}
+	}
+}
+
+func readWordSettings(f *zip.File, meta map[string]string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		t, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		var key string
+		switch t.Name.Local {
+		case "docId":
+			key = domain.MetaDocID
+		case "rsidRoot":
+			key = domain.MetaRsidRoot
+		default:
+			continue
+		}
+		for _, a := range t.Attr {
+			if a.Name.Local == "val" && a.Value != "" {
+				meta[key] = strings.Trim(a.Value, "{}")
+			}
+		}
+	}
+}
+
+func readODFTemplate(f *zip.File, meta map[string]string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if t, ok := tok.(xml.StartElement); ok && t.Name.Local == "template" {
+			for _, a := range t.Attr {
+				if a.Name.Local == "href" || (a.Name.Local == "title" && meta[domain.MetaTemplate] == "") {
+					meta[domain.MetaTemplate] = a.Value
+				}
+			}
+		}
+	}
+}
+
+// pdfMetadata runs pdfinfo on a temp copy of data; pdfinfo cannot read stdin.
+func pdfMetadata(ctx context.Context, data []byte) (map[string]string, error) {
+	f, err := os.CreateTemp("", "pdfinfo_input_*.pdf")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, err
+	}
+	f.Close()
+
+	cmd := commandContext(ctx, "pdfinfo", "-isodates", f.Name())
+	var out, errb bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errb
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("pdfinfo: %v: %s", err, errb.String())
+	}
+	meta := map[string]string{}
+	sc := bufio.NewScanner(&out)
+	for sc.Scan() {
+		k, v, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		if key := pdfInfoProps[strings.TrimSpace(k)]; key != "" {
+			if v = strings.TrimSpace(v); v != "" {
+				meta[key] = v
+			}
+		}
+	}
+	return meta, sc.Err()
+}
+
+func rtfMetadata(data []byte) map[string]string {
+	meta := map[string]string{}
+	for _, m := range rtfInfoRe.FindAllSubmatch(data, -1) {
+		if v := rtfText(m[2]); v != "" {
+			meta[rtfInfoProps[string(m[1])]] = v
+		}
+	}
+	return meta
+}
@@ -1,20 +1,33 @@
 package service
 
 import (
-	"regexp"
 	"strings"
+	"unicode"
 
 	"detector_plagio/backend/internal/ports"
 )
 
-var nonLetter = regexp.MustCompile(`[^a-zA-Z0-9áéíóúÁÉÍÓÚñÑüÜ]`)
+// IsWordRune reports whether Normalize keeps r, once lowercased, as part of
+// a word; every other rune becomes a space.
+func IsWordRune(r rune) bool {
+	r = unicode.ToLower(r)
+	return r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || strings.ContainsRune("áéíóúñü", r)
+}
 
 type SimpleNormalizer struct{}
 func NewNormalizer() ports.Normalizer { return &SimpleNormalizer{} }
 
+// Version must change whenever Normalize or Tokenize give different output.
+func (n *SimpleNormalizer) Version() string { return "1" }
+
 func (n *SimpleNormalizer) Normalize(s string) string {
 	s = strings.ToLower(s)
-	s = nonLetter.ReplaceAllString(s, " ")
+	s = strings.Map(func(r rune) rune {
+		if IsWordRune(r) {
+			return r
+		}
+		return ' '
+	}, s)
 	return strings.Join(strings.Fields(s), " ")
 }
 func (n *SimpleNormalizer) Tokenize(s string) []string {
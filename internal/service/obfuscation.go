@@ -0,0 +1,219 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+// confusables maps Cyrillic and Greek letters that render like Latin ones.
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'е': 'e', 'о': 'o', 'р': 'p', 'с': 'c', 'у': 'y', 'х': 'x', 'і': 'i', 'ј': 'j',
+	'ѕ': 's', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'һ': 'h', 'ӏ': 'l',
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O', 'Р': 'P', 'С': 'C',
+	'Т': 'T', 'Х': 'X', 'І': 'I', 'Ј': 'J', 'Ѕ': 'S', 'У': 'Y',
+	// Greek
+	'α': 'a', 'ο': 'o', 'ρ': 'p', 'ν': 'v', 'ι': 'i', 'κ': 'k', 'υ': 'u',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M', 'Ν': 'N',
+	'Ο': 'O', 'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X',
+}
+
+// zeroWidth characters are invisible and split a word for the normalizer.
+var zeroWidth = map[rune]bool{
+	'\u200B': true, '\u200C': true, '\u200D': true, '\u2060': true, '\u2061': true,
+	'\u2062': true, '\u2063': true, '\u2064': true, '\u180E': true, '\uFEFF': true,
+}
+
+// tinyFontHalfPoints is the largest w:sz (in half-points) treated as hidden: 1pt.
+const tinyFontHalfPoints = 2
+
+type ObfuscationDetector struct{}
+
+func NewObfuscationDetector() ports.ObfuscationAnalyzer { return &ObfuscationDetector{} }
+
+func (a *ObfuscationDetector) Version() string { return "1" }
+
+// Analyze removes zero-width characters and maps lookalike letters back to
+// Latin. A Cyrillic or Greek letter is only replaced inside a word that also
+// has Latin letters, or in a word made only of lookalikes within mostly Latin
+// text, so that genuine Russian or Greek passages are left alone.
+func (a *ObfuscationDetector) Analyze(text string, data []byte, ext string) (string, domain.Integrity) {
+	var in domain.Integrity
+	latinText := mostlyLatin(text)
+	var b strings.Builder
+	b.Grow(len(text))
+	var word []rune
+	flush := func() {
+		hasLatin, any, all := false, false, true
+		for i, r := range word {
+			if fw, ok := fullwidth(r); ok {
+				word[i] = fw
+				in.Homoglyphs++
+				r = fw
+			}
+			if _, ok := confusables[r]; ok {
+				any = true
+			} else {
+				all = false
+				if unicode.Is(unicode.Latin, r) {
+					hasLatin = true
+				}
+			}
+		}
+		if any && (hasLatin || (all && latinText)) {
+			for i, r := range word {
+				if c, ok := confusables[r]; ok {
+					word[i] = c
+					in.Homoglyphs++
+				}
+			}
+		}
+		b.WriteString(string(word))
+		word = word[:0]
+	}
+	for _, r := range text {
+		switch {
+		case zeroWidth[r]:
+			in.ZeroWidth++
+		case r == '\u00AD':
+			// soft hyphens are routine in typeset text; drop them without counting
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			word = append(word, r)
+		default:
+			flush()
+			b.WriteRune(r)
+		}
+	}
+	flush()
+
+	if ext == ".docx" {
+		in.HiddenRuns, in.HiddenChars = docxHiddenText(data)
+	}
+	in.Flags = []string{}
+	if in.Homoglyphs > 0 {
+		in.Flags = append(in.Flags, domain.FlagHomoglyphs)
+	}
+	if in.ZeroWidth > 0 {
+		in.Flags = append(in.Flags, domain.FlagZeroWidth)
+	}
+	if in.HiddenRuns > 0 {
+		in.Flags = append(in.Flags, domain.FlagHiddenText)
+	}
+	return b.String(), in
+}
+
+// fullwidth maps the fullwidth ASCII letters and digits (U+FF10..U+FF5A).
+func fullwidth(r rune) (rune, bool) {
+	switch {
+	case r >= '０' && r <= '９':
+		return r - '０' + '0', true
+	case r >= 'Ａ' && r <= 'Ｚ':
+		return r - 'Ａ' + 'A', true
+	case r >= 'ａ' && r <= 'ｚ':
+		return r - 'ａ' + 'a', true
+	}
+	return r, false
+}
+
+func mostlyLatin(text string) bool {
+	latin, other := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Cyrillic, r) || unicode.Is(unicode.Greek, r):
+			other++
+		}
+	}
+	return latin > other
+}
+
+// docxHiddenText counts the runs of word/document.xml that a reader cannot
+// see: w:vanish, white text without shading or highlight, or a 1pt font.
+// Hidden formatting inherited from styles.xml is not resolved.
+func docxHiddenText(data []byte) (runs, chars int) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return 0, 0
+	}
+	var body *zip.File
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			body = f
+		}
+	}
+	if body == nil {
+		return 0, 0
+	}
+	rc, err := body.Open()
+	if err != nil {
+		return 0, 0
+	}
+	defer rc.Close()
+
+	dec := xml.NewDecoder(rc)
+	var inRun, inText, vanish, white, shaded, tiny bool
+	n := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF || err != nil {
+			return runs, chars
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			val := xmlAttr(t, "val")
+			switch t.Name.Local {
+			case "r":
+				inRun, vanish, white, shaded, tiny, n = true, false, false, false, false, 0
+			case "t":
+				inText = inRun
+			case "vanish", "specVanish":
+				vanish = vanish || (inRun && val != "0" && val != "false" && val != "off")
+			case "color":
+				white = inRun && strings.EqualFold(val, "FFFFFF")
+			case "sz":
+				if v, err := strconv.Atoi(val); inRun && err == nil && v <= tinyFontHalfPoints {
+					tiny = true
+				}
+			case "shd":
+				fill := strings.ToUpper(xmlAttr(t, "fill"))
+				shaded = shaded || (inRun && fill != "" && fill != "AUTO" && fill != "FFFFFF")
+			case "highlight":
+				shaded = shaded || (inRun && val != "none" && val != "white")
+			}
+		case xml.CharData:
+			if inText {
+				n += utf8.RuneCount(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "r":
+				if n > 0 && (vanish || tiny || (white && !shaded)) {
+					runs++
+					chars += n
+				}
+				inRun = false
+			}
+		}
+	}
+}
+
+func xmlAttr(t xml.StartElement, name string) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"detector_plagio/backend/internal/ports"
+)
+
+// CommandOCR runs an external OCR command once per page. The command line is
+// split on spaces; {input} is replaced by the path of the document and {page}
+// by the page number, and the recognized text is read from stdout. Without an
+// {input} placeholder the path is appended as the last argument.
+type CommandOCR struct {
+	args    []string
+	timeout time.Duration
+}
+
+func NewCommandOCR(command string, timeout time.Duration) ports.OCR {
+	args := splitCommand(command)
+	if !strings.Contains(command, "{input}") {
+		args = append(args, "{input}")
+	}
+	return &CommandOCR{args: args, timeout: timeout}
+}
+
+func (o *CommandOCR) Recognize(ctx context.Context, data []byte, ext string, pages []int) (map[int]string, error) {
+	f, err := os.CreateTemp("", "ocr_input_*"+ext)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, err
+	}
+	f.Close()
+
+	out := map[int]string{}
+	var errs []error
+	for _, p := range pages {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+		text, err := o.page(ctx, f.Name(), p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("page %d: %w", p, err))
+			continue
+		}
+		out[p] = text
+	}
+	log.Printf("CommandOCR: recognized %d of %d pages", len(out), len(pages))
+	return out, errors.Join(errs...)
+}
+
+func (o *CommandOCR) page(ctx context.Context, input string, page int) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+	r := strings.NewReplacer("{input}", input, "{page}", strconv.Itoa(page))
+	args := make([]string, len(o.args))
+	for i, a := range o.args {
+		args[i] = r.Replace(a)
+	}
+	cmd := commandContext(ctx, args[0], args[1:]...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", &ports.ToolError{Tool: "ocr", Err: ctx.Err()}
+		}
+		return "", &ports.ToolError{Tool: "ocr", Err: err, Stderr: strings.TrimSpace(stderr.String())}
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// splitCommand splits on spaces, keeping single- or double-quoted words whole.
+func splitCommand(s string) []string {
+	var args []string
+	var cur strings.Builder
+	var quote rune
+	inWord := false
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			cur.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				args = append(args, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		args = append(args, cur.String())
+	}
+	return args
+}
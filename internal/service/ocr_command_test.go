@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"detector_plagio/backend/internal/ports"
+)
+
+func TestCommandOCR(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "ocr")
+	body := `#!/bin/sh
+[ -s "$2" ] || exit 2
+case "$1" in 3) echo "no text" >&2; exit 1;; esac
+echo "  page $1 of $(basename "$2" | cut -c1-10)  "
+`
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	ocr := NewCommandOCR(script+" {page}", time.Minute)
+	got, err := ocr.Recognize(context.Background(), []byte("%PDF-1.4"), ".pdf", []int{1, 3, 4})
+	want := map[int]string{1: "page 1 of ocr_input_", 4: "page 4 of ocr_input_"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Recognize = %q, want %q", got, want)
+	}
+	var tool *ports.ToolError
+	if !errors.As(err, &tool) || tool.Stderr != "no text" {
+		t.Errorf("err = %v, want the ToolError of page 3", err)
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"tesseract {input} - -l spa", []string{"tesseract", "{input}", "-", "-l", "spa"}},
+		{"  a   b\tc ", []string{"a", "b", "c"}},
+		{`sh -c 'pdftoppm -f $1 | tesseract - -' {input} {page}`, []string{"sh", "-c", "pdftoppm -f $1 | tesseract - -", "{input}", "{page}"}},
+		{`cmd "two words" ''`, []string{"cmd", "two words", ""}},
+	}
+	for _, c := range cases {
+		if got := splitCommand(c.in); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("splitCommand(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// pdfWriter produces a minimal PDF 1.4 file: A4 pages drawn with the
+// standard Helvetica fonts, which every reader provides, so there is
+// nothing to embed and no dependency to fetch.
+type pdfWriter struct {
+	pages []*bytes.Buffer
+}
+
+const (
+	pdfPageW = 595.0
+	pdfPageH = 842.0
+)
+
+func (p *pdfWriter) newPage() *bytes.Buffer {
+	b := &bytes.Buffer{}
+	p.pages = append(p.pages, b)
+	return b
+}
+
+// pdfText draws s with its baseline at (x, y); font is "F1" (regular) or "F2" (bold).
+func pdfText(b *bytes.Buffer, font string, size, x, y float64, s string) {
+	fmt.Fprintf(b, "BT /%s %s Tf %s %s Td (", font, pdfNum(size), pdfNum(x), pdfNum(y))
+	b.Write(pdfEscape(winAnsi(s)))
+	b.WriteString(") Tj ET\n")
+}
+
+// pdfRect fills a rectangle whose lower-left corner is (x, y) with an
+// "#rrggbb" color.
+func pdfRect(b *bytes.Buffer, x, y, w, h float64, color string) {
+	r, g, bl := hexColor(color)
+	fmt.Fprintf(b, "q %s %s %s rg %s %s %s %s re f Q\n", pdfNum(r), pdfNum(g), pdfNum(bl), pdfNum(x), pdfNum(y), pdfNum(w), pdfNum(h))
+}
+
+func pdfLine(b *bytes.Buffer, x1, y1, x2, y2 float64) {
+	fmt.Fprintf(b, "q 0.8 G 0.5 w %s %s m %s %s l S Q\n", pdfNum(x1), pdfNum(y1), pdfNum(x2), pdfNum(y2))
+}
+
+// bytes assembles the objects and the cross-reference table.
+func (p *pdfWriter) bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 catalog, 2 page tree, 3-4 fonts, then a page and its content per page
+	kids := ""
+	for i := range p.pages {
+		kids += fmt.Sprintf("%d 0 R ", 5+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, len(p.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range p.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfNum(pdfPageW), pdfNum(pdfPageH), 6+2*i))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+func pdfNum(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+
+func pdfEscape(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for _, c := range b {
+		if c == '(' || c == ')' || c == '\\' {
+			out = append(out, '\\')
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// winAnsiExtra maps the characters of code page 1252 outside Latin-1.
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// winAnsi encodes s for the standard fonts; characters they lack become "?".
+func winAnsi(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			out = append(out, ' ')
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			out = append(out, byte(r))
+		case winAnsiExtra[r] != 0:
+			out = append(out, winAnsiExtra[r])
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+// helveticaWidths are the Helvetica advance widths of ASCII 32-126 in
+// thousandths of the font size.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// textWidth estimates the width of s in points. Accented letters are
+// measured as 556, the width of most lowercase letters; bold runs about 5%
+// wider than regular.
+func textWidth(s string, size float64, bold bool) float64 {
+	w := 0
+	for _, r := range s {
+		if r >= 32 && r < 127 {
+			w += helveticaWidths[r-32]
+		} else {
+			w += 556
+		}
+	}
+	f := float64(w) * size / 1000
+	if bold {
+		f *= 1.05
+	}
+	return f
+}
+
+func hexColor(s string) (float64, float64, float64) {
+	if len(s) != 7 || s[0] != '#' {
+		return 1, 1, 1
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return 1, 1, 1
+	}
+	return float64(v>>16&0xff) / 255, float64(v>>8&0xff) / 255, float64(v&0xff) / 255
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package service
+
+import "os/exec"
+
+// killProcessGroup falls back to exec's default of killing only the direct child.
+func killProcessGroup(cmd *exec.Cmd) {}
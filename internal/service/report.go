@@ -0,0 +1,112 @@
+package service
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"detector_plagio/backend/internal/domain"
+)
+
+// reportPalette colors the passages of a report; passage n uses entry
+// (n-1) mod len.
+var reportPalette = []string{"#ffe08a", "#a8e6a1", "#9fd3ff", "#ffb3c7", "#d3b8ff", "#ffc98f", "#9ee8e0", "#e0e0a0"}
+
+func passageColor(n int) string { return reportPalette[(n-1)%len(reportPalette)] }
+
+// normalizedTextNote explains that the texts section shows the text layer
+// the comparison ran on, not the original document.
+const normalizedTextNote = "These are the texts as compared, not the original files: lowercased and " +
+	"reduced to the letters a-z, á, é, í, ó, ú, ñ, ü and digits, with everything else turned into spaces."
+
+// reportPiece is a run of text that is either unmatched (Match 0) or part of
+// passage number Match.
+type reportPiece struct {
+	Text  string
+	Match int
+}
+
+// highlight cuts text into pieces at the given passages; ranges are rune
+// offsets, and where two passages overlap the earlier one keeps the text.
+func highlight(text string, passages []domain.ReportPassage, inA bool) []reportPiece {
+	type mark struct{ start, end, n int }
+	runes := []rune(text)
+	marks := make([]mark, 0, len(passages))
+	for i, p := range passages {
+		m := mark{p.BStart, p.BEnd, i + 1}
+		if inA {
+			m = mark{p.AStart, p.AEnd, i + 1}
+		}
+		if m.start < 0 || m.end > len(runes) || m.start >= m.end {
+			continue
+		}
+		marks = append(marks, m)
+	}
+	sort.SliceStable(marks, func(i, j int) bool { return marks[i].start < marks[j].start })
+	var out []reportPiece
+	pos := 0
+	for _, m := range marks {
+		if m.end <= pos {
+			continue
+		}
+		if m.start > pos {
+			out = append(out, reportPiece{Text: string(runes[pos:m.start])})
+		} else {
+			m.start = pos
+		}
+		out = append(out, reportPiece{Text: string(runes[m.start:m.end]), Match: m.n})
+		pos = m.end
+	}
+	if pos < len(runes) {
+		out = append(out, reportPiece{Text: string(runes[pos:])})
+	}
+	return out
+}
+
+// passageText returns the text of passage p in document A or B.
+func passageText(rep domain.ComparisonReport, p domain.ReportPassage, inA bool) string {
+	text, start, end := rep.DocB.TextContent, p.BStart, p.BEnd
+	if inA {
+		text, start, end = rep.DocA.TextContent, p.AStart, p.AEnd
+	}
+	runes := []rune(text)
+	if start < 0 || end > len(runes) || start >= end {
+		return ""
+	}
+	return string(runes[start:end])
+}
+
+// docFacts lists the document fields printed in the metadata section.
+func docFacts(d domain.Document) []domain.ReportScore {
+	facts := []domain.ReportScore{
+		{Label: "File", Value: d.OriginalFilename},
+		{Label: "ID", Value: d.ID},
+		{Label: "Folder", Value: d.Folder},
+		{Label: "Owner", Value: d.Owner},
+		{Label: "Role", Value: d.CorpusRole()},
+		{Label: "Type", Value: d.Ext + " " + d.MimeType},
+		{Label: "Size", Value: strconv.FormatInt(d.Size, 10) + " bytes"},
+		{Label: "SHA-256", Value: d.SHA256},
+		{Label: "Updated", Value: d.UpdatedAt},
+	}
+	// drop what the document does not have
+	kept := facts[:0]
+	for _, f := range facts {
+		if strings.TrimSpace(f.Value) != "" {
+			kept = append(kept, f)
+		}
+	}
+	facts = kept
+	keys := make([]string, 0, len(d.Metadata))
+	for k := range d.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		facts = append(facts, domain.ReportScore{Label: k, Value: d.Metadata[k]})
+	}
+	if d.Integrity != nil && len(d.Integrity.Flags) > 0 {
+		facts = append(facts, domain.ReportScore{Label: "Integrity flags", Value: strings.Join(d.Integrity.Flags, ", ")})
+	}
+	return facts
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"html/template"
+	"io"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+type HTMLReport struct{}
+
+func NewHTMLReport() ports.ReportRenderer { return &HTMLReport{} }
+
+func (r *HTMLReport) ContentType() string { return "text/html; charset=utf-8" }
+func (r *HTMLReport) Extension() string   { return ".html" }
+
+type htmlPassage struct {
+	N            int
+	Color        template.CSS
+	TextA, TextB string
+}
+
+type htmlReportData struct {
+	domain.ComparisonReport
+	Generated    string
+	FactsA       []domain.ReportScore
+	FactsB       []domain.ReportScore
+	Passages     []htmlPassage
+	TextA, TextB []reportPiece
+	TextNote     string
+}
+
+func (r *HTMLReport) Render(w io.Writer, rep domain.ComparisonReport) error {
+	data := htmlReportData{
+		ComparisonReport: rep,
+		Generated:        rep.GeneratedAt.Format("2006-01-02 15:04:05 MST"),
+		FactsA:           docFacts(rep.DocA),
+		FactsB:           docFacts(rep.DocB),
+		TextA:            highlight(rep.DocA.TextContent, rep.Passages, true),
+		TextB:            highlight(rep.DocB.TextContent, rep.Passages, false),
+		TextNote:         normalizedTextNote,
+	}
+	for i, p := range rep.Passages {
+		data.Passages = append(data.Passages, htmlPassage{
+			N: i + 1, Color: template.CSS(passageColor(i + 1)),
+			TextA: passageText(rep, p, true), TextB: passageText(rep, p, false),
+		})
+	}
+	return htmlReportTemplate.Execute(w, data)
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"color": func(n int) template.CSS { return template.CSS(passageColor(n)) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Comparison: {{.DocA.OriginalFilename}} / {{.DocB.OriginalFilename}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.5em; margin-bottom: 0; }
+h2 { font-size: 1.15em; border-bottom: 1px solid #ccc; padding-bottom: .2em; margin-top: 2em; }
+.generated, .note { color: #666; margin-top: .3em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; vertical-align: top; padding: .3em .5em; border-bottom: 1px solid #eee; }
+th { width: 12em; font-weight: 600; }
+.cols { display: flex; gap: 2em; }
+.cols > div { flex: 1; min-width: 0; }
+.text { white-space: pre-wrap; line-height: 1.5; border: 1px solid #ddd; padding: 1em; }
+.facts td { word-break: break-all; }
+mark { padding: 0 .1em; }
+mark sup { font-size: .7em; color: #555; }
+.n { font-weight: 600; width: 2em; }
+</style>
+</head>
+<body>
+<h1>Comparison report</h1>
+<p class="generated">Generated {{.Generated}}</p>
+
+<h2>Documents</h2>
+<div class="cols">
+<div><h3>A</h3><table class="facts">{{range .FactsA}}<tr><th>{{.Label}}</th><td>{{.Value}}</td></tr>{{end}}</table></div>
+<div><h3>B</h3><table class="facts">{{range .FactsB}}<tr><th>{{.Label}}</th><td>{{.Value}}</td></tr>{{end}}</table></div>
+</div>
+
+<h2>Scores</h2>
+<table>{{range .Scores}}<tr><th>{{.Label}}</th><td>{{.Value}}</td></tr>{{end}}</table>
+
+<h2>Matching passages ({{len .Passages}})</h2>
+{{if .Passages}}<table>
+<tr><th class="n">#</th><th>A</th><th>B</th></tr>
+{{range .Passages}}<tr style="background: {{.Color}}"><td class="n">{{.N}}</td><td>{{.TextA}}</td><td>{{.TextB}}</td></tr>
+{{end}}</table>{{else}}<p>No shared passages.</p>{{end}}
+
+<h2>Normalized texts</h2>
+<p class="note">{{.TextNote}}</p>
+<div class="cols">
+<div><h3>A: {{.DocA.OriginalFilename}}</h3><div class="text">{{range .TextA}}{{if .Match}}<mark style="background: {{color .Match}}">{{.Text}}<sup>{{.Match}}</sup></mark>{{else}}{{.Text}}{{end}}{{end}}</div></div>
+<div><h3>B: {{.DocB.OriginalFilename}}</h3><div class="text">{{range .TextB}}{{if .Match}}<mark style="background: {{color .Match}}">{{.Text}}<sup>{{.Match}}</sup></mark>{{else}}{{.Text}}{{end}}{{end}}</div></div>
+</div>
+
+<h2>Similar sentences ({{len .Segments}})</h2>
+{{if .Segments}}<table>
+<tr><th>A</th><th>B</th><th class="n">Score</th></tr>
+{{range .Segments}}<tr><td>{{.TextA}}</td><td>{{.TextB}}</td><td>{{printf "%.2f" .Score}}</td></tr>
+{{end}}</table>{{else}}<p>No similar sentences.</p>{{end}}
+</body>
+</html>
+`))
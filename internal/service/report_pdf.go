@@ -0,0 +1,214 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+type PDFReport struct{}
+
+func NewPDFReport() ports.ReportRenderer { return &PDFReport{} }
+
+func (r *PDFReport) ContentType() string { return "application/pdf" }
+func (r *PDFReport) Extension() string   { return ".pdf" }
+
+const (
+	pdfMargin  = 50.0
+	pdfBody    = 9.0
+	pdfLeading = 12.0
+)
+
+// pdfLayout flows content top to bottom, opening pages as needed.
+type pdfLayout struct {
+	doc  pdfWriter
+	page *bytes.Buffer
+	y    float64
+}
+
+func (l *pdfLayout) newPage() {
+	l.page = l.doc.newPage()
+	l.y = pdfPageH - pdfMargin
+}
+
+// room starts a new page unless h more points fit on this one.
+func (l *pdfLayout) room(h float64) {
+	if l.page == nil || l.y-h < pdfMargin {
+		l.newPage()
+	}
+}
+
+func (l *pdfLayout) heading(s string, size float64) {
+	l.room(size*2 + pdfLeading)
+	l.y -= size + 8
+	pdfText(l.page, "F2", size, pdfMargin, l.y, s)
+	l.y -= 6
+	pdfLine(l.page, pdfMargin, l.y, pdfPageW-pdfMargin, l.y)
+	l.y -= 4
+}
+
+func (l *pdfLayout) line(font string, size float64, s string) {
+	l.room(pdfLeading)
+	l.y -= pdfLeading
+	pdfText(l.page, font, size, pdfMargin, l.y, s)
+}
+
+// rows prints label/value pairs, wrapping the value.
+func (l *pdfLayout) rows(rows []domain.ReportScore) {
+	const labelW = 120.0
+	width := pdfPageW - 2*pdfMargin - labelW
+	for _, r := range rows {
+		for i, v := range wrapText(r.Value, pdfBody, width) {
+			l.room(pdfLeading)
+			l.y -= pdfLeading
+			if i == 0 {
+				pdfText(l.page, "F2", pdfBody, pdfMargin, l.y, r.Label)
+			}
+			pdfText(l.page, "F1", pdfBody, pdfMargin+labelW, l.y, v)
+		}
+	}
+}
+
+// columns prints a and b side by side on a colored band, continuing on the
+// next page when they do not fit.
+func (l *pdfLayout) columns(label, a, b, color string) {
+	const labelW, gap = 20.0, 10.0
+	colW := (pdfPageW - 2*pdfMargin - labelW - gap) / 2
+	la, lb := wrapText(a, pdfBody, colW-4), wrapText(b, pdfBody, colW-4)
+	n := max(len(la), len(lb))
+	for i := 0; i < n; {
+		l.room(pdfLeading + 4)
+		fit := min(n-i, int((l.y-pdfMargin-4)/pdfLeading))
+		top := l.y
+		pdfRect(l.page, pdfMargin, top-float64(fit)*pdfLeading-4, pdfPageW-2*pdfMargin, float64(fit)*pdfLeading+2, color)
+		if i == 0 {
+			pdfText(l.page, "F2", pdfBody, pdfMargin+2, top-pdfLeading, label)
+		}
+		for k := 0; k < fit; k++ {
+			l.y -= pdfLeading
+			if i+k < len(la) {
+				pdfText(l.page, "F1", pdfBody, pdfMargin+labelW, l.y, la[i+k])
+			}
+			if i+k < len(lb) {
+				pdfText(l.page, "F1", pdfBody, pdfMargin+labelW+colW+gap, l.y, lb[i+k])
+			}
+		}
+		l.y -= 6
+		i += fit
+	}
+}
+
+// flow prints a document text word by word, painting the background of the
+// words that belong to a passage with the passage color.
+func (l *pdfLayout) flow(pieces []reportPiece) {
+	right := pdfPageW - pdfMargin
+	x := pdfMargin
+	l.room(pdfLeading)
+	l.y -= pdfLeading
+	space := textWidth(" ", pdfBody, false)
+	for _, p := range pieces {
+		for i, para := range strings.Split(p.Text, "\n") {
+			if i > 0 {
+				x = pdfMargin
+				l.room(pdfLeading)
+				l.y -= pdfLeading
+			}
+			var words []string
+			for _, word := range strings.Fields(para) {
+				words = append(words, wrapText(word, pdfBody, right-pdfMargin)...)
+			}
+			for _, word := range words {
+				w := textWidth(word, pdfBody, false)
+				if x+w > right && x > pdfMargin {
+					x = pdfMargin
+					l.room(pdfLeading)
+					l.y -= pdfLeading
+				}
+				if p.Match > 0 {
+					pdfRect(l.page, x-1, l.y-3, w+space, pdfLeading-1, passageColor(p.Match))
+				}
+				pdfText(l.page, "F1", pdfBody, x, l.y, word)
+				x += w + space
+			}
+		}
+	}
+	l.y -= 6
+}
+
+func (r *PDFReport) Render(w io.Writer, rep domain.ComparisonReport) error {
+	var l pdfLayout
+	l.heading("Comparison report", 16)
+	l.line("F1", pdfBody, "Generated "+rep.GeneratedAt.Format("2006-01-02 15:04:05 MST"))
+
+	l.heading("Document A", 12)
+	l.rows(docFacts(rep.DocA))
+	l.heading("Document B", 12)
+	l.rows(docFacts(rep.DocB))
+	l.heading("Scores", 12)
+	l.rows(rep.Scores)
+
+	l.heading(fmt.Sprintf("Matching passages (%d)", len(rep.Passages)), 12)
+	if len(rep.Passages) == 0 {
+		l.line("F1", pdfBody, "No shared passages.")
+	}
+	for i, p := range rep.Passages {
+		l.columns(fmt.Sprint(i+1), passageText(rep, p, true), passageText(rep, p, false), passageColor(i+1))
+	}
+
+	l.heading(fmt.Sprintf("Similar sentences (%d)", len(rep.Segments)), 12)
+	if len(rep.Segments) == 0 {
+		l.line("F1", pdfBody, "No similar sentences.")
+	}
+	for _, s := range rep.Segments {
+		l.columns(fmt.Sprintf("%.2f", s.Score), s.TextA, s.TextB, "#f2f2f2")
+	}
+
+	l.heading("Normalized text A: "+rep.DocA.OriginalFilename, 12)
+	for _, s := range wrapText(normalizedTextNote, pdfBody, pdfPageW-2*pdfMargin) {
+		l.line("F1", pdfBody, s)
+	}
+	l.flow(highlight(rep.DocA.TextContent, rep.Passages, true))
+	l.heading("Normalized text B: "+rep.DocB.OriginalFilename, 12)
+	l.flow(highlight(rep.DocB.TextContent, rep.Passages, false))
+
+	_, err := w.Write(l.doc.bytes())
+	return err
+}
+
+// wrapText breaks s into lines no wider than width; a word longer than a
+// line is cut.
+func wrapText(s string, size, width float64) []string {
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			for textWidth(word, size, false) > width {
+				cut := len([]rune(word)) - 1
+				for cut > 1 && textWidth(string([]rune(word)[:cut]), size, false) > width {
+					cut--
+				}
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				lines = append(lines, string([]rune(word)[:cut]))
+				word = string([]rune(word)[cut:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case textWidth(line+" "+word, size, false) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
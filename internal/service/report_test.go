@@ -0,0 +1,96 @@
+package service
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"detector_plagio/backend/internal/domain"
+)
+
+func TestHighlight(t *testing.T) {
+	text := "año uno dos tres cuatro"
+	cases := []struct {
+		name     string
+		passages []domain.ReportPassage
+		inA      bool
+		want     []reportPiece
+	}{
+		{"no passages", nil, true, []reportPiece{{Text: text}}},
+		{
+			"rune offsets in A", []domain.ReportPassage{{AStart: 0, AEnd: 3, BStart: 8, BEnd: 11}}, true,
+			[]reportPiece{{Text: "año", Match: 1}, {Text: " uno dos tres cuatro"}},
+		},
+		{
+			"offsets in B", []domain.ReportPassage{{AStart: 0, AEnd: 3, BStart: 8, BEnd: 11}}, false,
+			[]reportPiece{{Text: "año uno "}, {Text: "dos", Match: 1}, {Text: " tres cuatro"}},
+		},
+		{
+			"out of order and overlapping", []domain.ReportPassage{{AStart: 12, AEnd: 23}, {AStart: 4, AEnd: 16}}, true,
+			[]reportPiece{{Text: "año "}, {Text: "uno dos tres", Match: 2}, {Text: " cuatro", Match: 1}},
+		},
+		{
+			"contained passage dropped", []domain.ReportPassage{{AStart: 4, AEnd: 16}, {AStart: 8, AEnd: 11}}, true,
+			[]reportPiece{{Text: "año "}, {Text: "uno dos tres", Match: 1}, {Text: " cuatro"}},
+		},
+		{
+			"invalid ranges ignored", []domain.ReportPassage{{AStart: -1, AEnd: 3}, {AStart: 5, AEnd: 5}, {AStart: 20, AEnd: 99}}, true,
+			[]reportPiece{{Text: text}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := highlight(text, c.passages, c.inA); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("highlight = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	const size = 10
+	cases := []struct {
+		name  string
+		text  string
+		width float64
+		want  []string
+	}{
+		{"fits", "aaaa aaaa", textWidth("aaaa aaaa", size, false), []string{"aaaa aaaa"}},
+		{"wraps at words", "aaaa aaaa aaaa", textWidth("aaaa aaaa", size, false), []string{"aaaa aaaa", "aaaa"}},
+		{"cuts long words", "aa aaaaaaaaaaaa", textWidth("aaaaa", size, false), []string{"aa", "aaaaa", "aaaaa", "aa"}},
+		{"keeps paragraphs", "uno\n\ndos", 100, []string{"uno", "", "dos"}},
+		{"collapses spaces", "  uno   dos  ", 100, []string{"uno dos"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := wrapText(c.text, size, c.width)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("wrapText = %q, want %q", got, c.want)
+			}
+			for _, line := range got {
+				if textWidth(line, size, false) > c.width {
+					t.Errorf("line %q is wider than %.1f", line, c.width)
+				}
+			}
+		})
+	}
+}
+
+func TestHTMLReportLabelsNormalizedText(t *testing.T) {
+	rep := domain.ComparisonReport{
+		DocA:     domain.Document{OriginalFilename: "a.docx", TextContent: "uno dos tres"},
+		DocB:     domain.Document{OriginalFilename: "b.docx", TextContent: "cero uno dos"},
+		Passages: []domain.ReportPassage{{AStart: 0, AEnd: 7, BStart: 5, BEnd: 12}},
+	}
+	var buf bytes.Buffer
+	if err := NewHTMLReport().Render(&buf, rep); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, want := range []string{"<h2>Normalized texts</h2>", "not the original files", "uno dos<sup>1</sup></mark>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report lacks %q", want)
+		}
+	}
+}
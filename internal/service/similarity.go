@@ -15,111 +15,100 @@ func NewSimilarity() ports.Similarity { return &SimilarityService{} }
 
 func (s *SimilarityService) Jaccard(a, b []string) ports.JaccardResult {
 	log.Printf("Jaccard called with len(a)=%d, len(b)=%d", len(a), len(b))
-	set := func(xs []string) map[string]bool {
-		m := map[string]bool{}
-		for _, x := range xs {
-			m[x] = true
-		}
-		return m
-	}
-	A := set(a)
-	B := set(b)
+	res := s.JaccardSets(termSet(a), termSet(b))
+	log.Printf("Jaccard: intersection=%d, union=%d, score=%f", res.Intersection, res.Union, res.Score)
+	return res
+}
+
+// JaccardSets scores two sets; two empty sets count as identical.
+func (s *SimilarityService) JaccardSets(A, B map[string]bool) ports.JaccardResult {
 	if len(A) == 0 && len(B) == 0 {
-		log.Println("Jaccard: both empty, returning 1.0")
 		return ports.JaccardResult{Intersection: 0, Union: 0, Score: 1.0}
 	}
-	inter, uni := 0, 0
-	seen := map[string]bool{}
+	if len(A) > len(B) {
+		A, B = B, A
+	}
+	inter := 0
 	for k := range A {
-		seen[k] = true
 		if B[k] {
 			inter++
 		}
-		uni++
 	}
-	for k := range B {
-		if !seen[k] {
-			uni++
-		}
-	}
-	if uni == 0 {
-		log.Println("Jaccard: union empty, returning 0")
-		return ports.JaccardResult{Intersection: inter, Union: uni, Score: 0}
-	}
-	score := float64(inter) / float64(uni)
-	log.Printf("Jaccard: intersection=%d, union=%d, score=%f", inter, uni, score)
-	return ports.JaccardResult{Intersection: inter, Union: uni, Score: score}
+	uni := len(A) + len(B) - inter
+	return ports.JaccardResult{Intersection: inter, Union: uni, Score: float64(inter) / float64(uni)}
 }
 
 func (s *SimilarityService) CosineTFIDF(aTokens, bTokens []string) ports.CosineTFIDFResult {
 	log.Printf("CosineTFIDF called with len(aTokens)=%d, len(bTokens)=%d", len(aTokens), len(bTokens))
+	res := s.CosineCounts(termCounts(aTokens), termCounts(bTokens))
+	log.Printf("CosineTFIDF: dot=%f, na2=%f, nb2=%f, score=%f", res.Dot, res.Na2, res.Nb2, res.Score)
+	return res
+}
 
-	// Term frequency for document A
-	tfa := make(map[string]int)
-	for _, token := range aTokens {
-		tfa[token]++
-	}
-
-	// Term frequency for document B
-	tfb := make(map[string]int)
-	for _, token := range bTokens {
-		tfb[token]++
-	}
-
-	// Document frequency (in our case, the corpus is just two documents)
-	df := make(map[string]int)
-	for token := range tfa {
-		df[token]++
-	}
-	for token := range tfb {
-		df[token]++
-	}
-
-	// All unique terms in both documents
-	allTerms := make(map[string]bool)
-	for token := range tfa {
-		allTerms[token] = true
-	}
-	for token := range tfb {
-		allTerms[token] = true
-	}
-
+// CosineCounts is the TF-IDF cosine of two term counts, taking the two
+// documents as the whole corpus: a shared term has idf 1 and a term of only
+// one document 1+ln 2, so only the shared terms add to the dot product.
+func (s *SimilarityService) CosineCounts(tfa, tfb map[string]int) ports.CosineTFIDFResult {
+	idfOne := 1.0 + math.Log(2.0)
 	var dot, na2, nb2 float64
-
-	for term := range allTerms {
-		// TF-IDF for term in document A
-		tfA := float64(tfa[term])
-		idfA := 1.0 + math.Log(2.0/float64(df[term]))
-		tfidfA := tfA * idfA
-
-		// TF-IDF for term in document B
-		tfB := float64(tfb[term])
-		idfB := 1.0 + math.Log(2.0/float64(df[term]))
-		tfidfB := tfB * idfB
-
-		dot += tfidfA * tfidfB
-		na2 += tfidfA * tfidfA
-		nb2 += tfidfB * tfidfB
+	for term, n := range tfa {
+		a := float64(n)
+		if m, ok := tfb[term]; ok {
+			dot += a * float64(m)
+		} else {
+			a *= idfOne
+		}
+		na2 += a * a
+	}
+	for term, n := range tfb {
+		b := float64(n)
+		if _, ok := tfa[term]; !ok {
+			b *= idfOne
+		}
+		nb2 += b * b
 	}
-
 	score := 0.0
 	if na2 > 0 && nb2 > 0 {
 		score = dot / (math.Sqrt(na2) * math.Sqrt(nb2))
 	}
-
-	log.Printf("CosineTFIDF: dot=%f, na2=%f, nb2=%f, score=%f", dot, na2, nb2, score)
 	return ports.CosineTFIDFResult{Dot: dot, Na2: na2, Nb2: nb2, Score: score}
 }
 
+// termSet returns the distinct strings of xs.
+func termSet(xs []string) map[string]bool {
+	m := make(map[string]bool, len(xs))
+	for _, x := range xs {
+		m[x] = true
+	}
+	return m
+}
+
+// termCounts returns how often each string occurs in xs.
+func termCounts(xs []string) map[string]int {
+	m := make(map[string]int, len(xs))
+	for _, x := range xs {
+		m[x]++
+	}
+	return m
+}
+
 func (s *SimilarityService) CompareSegments(textA, textB string) ([]ports.MatchingSegment, error) {
 	segmentsA := s.segmentText(textA)
 	segmentsB := s.segmentText(textB)
+	words := func(segs []string) []map[string]bool {
+		out := make([]map[string]bool, len(segs))
+		for i, seg := range segs {
+			out[i] = termSet(strings.Fields(strings.ToLower(seg)))
+		}
+		return out
+	}
+	wordsA, wordsB := words(segmentsA), words(segmentsB)
 
 	var matches []ports.MatchingSegment
-	for _, segA := range segmentsA {
-		for _, segB := range segmentsB {
+	for i, segA := range segmentsA {
+		for j, segB := range segmentsB {
 			// Use Jaccard similarity for segment comparison
-			jaccardResult := s.Jaccard(strings.Fields(strings.ToLower(segA)), strings.Fields(strings.ToLower(segB)))
+			jaccardResult := s.JaccardSets(wordsA[i], wordsB[j])
 			if jaccardResult.Score > 0.5 { // Threshold for considering a match
 				matches = append(matches, ports.MatchingSegment{
 					TextA: segA,
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// cosineReference is the two-document TF-IDF cosine spelled out term by term.
+func cosineReference(a, b []string) float64 {
+	tfa, tfb := termCounts(a), termCounts(b)
+	all := termSet(append(append([]string{}, a...), b...))
+	var dot, na2, nb2 float64
+	for t := range all {
+		df := 0
+		if tfa[t] > 0 {
+			df++
+		}
+		if tfb[t] > 0 {
+			df++
+		}
+		idf := 1 + math.Log(2/float64(df))
+		wa, wb := float64(tfa[t])*idf, float64(tfb[t])*idf
+		dot += wa * wb
+		na2 += wa * wa
+		nb2 += wb * wb
+	}
+	if na2 == 0 || nb2 == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na2) * math.Sqrt(nb2))
+}
+
+func TestSimilarityScores(t *testing.T) {
+	s := &SimilarityService{}
+	cases := []struct {
+		a, b    string
+		jaccard float64
+	}{
+		{"", "", 1},
+		{"uno dos", "", 0},
+		{"uno dos tres", "uno dos tres", 1},
+		{"uno dos tres", "tres cuatro", 0.25},
+		{"el el el gato", "el perro perro", 1.0 / 3},
+	}
+	for _, c := range cases {
+		a, b := strings.Fields(c.a), strings.Fields(c.b)
+		j := s.Jaccard(a, b)
+		if js := s.JaccardSets(termSet(a), termSet(b)); js != j || math.Abs(j.Score-c.jaccard) > 1e-9 {
+			t.Errorf("Jaccard(%q, %q) = %+v, sets %+v, want score %f", c.a, c.b, j, js, c.jaccard)
+		}
+		cos := s.CosineTFIDF(a, b)
+		// the sums run in map order, so repeated calls may differ in the last bits
+		if cc := s.CosineCounts(termCounts(a), termCounts(b)); math.Abs(cc.Score-cos.Score) > 1e-12 {
+			t.Errorf("CosineCounts(%q, %q) = %+v, CosineTFIDF %+v", c.a, c.b, cc, cos)
+		}
+		if want := cosineReference(a, b); math.Abs(cos.Score-want) > 1e-9 {
+			t.Errorf("CosineTFIDF(%q, %q) = %f, want %f", c.a, c.b, cos.Score, want)
+		}
+	}
+}
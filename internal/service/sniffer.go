@@ -0,0 +1,167 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"detector_plagio/backend/internal/ports"
+)
+
+const (
+	MimePDF     = "application/pdf"
+	MimeDOCX    = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MimeXLSX    = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	MimePPTX    = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+	MimeODT     = "application/vnd.oasis.opendocument.text"
+	MimeRTF     = "application/rtf"
+	MimeDOC     = "application/msword"
+	MimeHTML    = "text/html"
+	MimeZip     = "application/zip"
+	MimeText    = "text/plain"
+	MimeUnknown = "application/octet-stream"
+)
+
+// sniffLen is how much of the upload is inspected for magic bytes and text heuristics.
+const sniffLen = 8192
+
+// odfExts maps the ODF "mimetype" entry to the extension we store it under.
+var odfExts = map[string]string{
+	MimeODT: ".odt",
+	"application/vnd.oasis.opendocument.spreadsheet":  ".ods",
+	"application/vnd.oasis.opendocument.presentation": ".odp",
+}
+
+// textExts are the declared extensions that accept any plain-text payload.
+var textExts = map[string]bool{".txt": true, ".md": true, ".markdown": true, ".html": true, ".htm": true}
+
+// oleMagic opens every OLE2 compound file, which is how legacy .doc is stored.
+var oleMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+type ContentSniffer struct{}
+
+func NewContentSniffer() ports.TypeDetector { return &ContentSniffer{} }
+
+func (s *ContentSniffer) Detect(data []byte) ports.DetectedType {
+	head := data
+	if len(head) > sniffLen {
+		head = head[:sniffLen]
+	}
+	switch {
+	case len(head) == 0:
+		return ports.DetectedType{MIME: MimeUnknown}
+	case bytes.HasPrefix(bytes.TrimLeft(bytes.TrimPrefix(head, []byte{0xEF, 0xBB, 0xBF}), " \t\r\n"), []byte("%PDF-")):
+		return ports.DetectedType{MIME: MimePDF, Ext: ".pdf"}
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		return sniffZip(data)
+	case bytes.HasPrefix(head, []byte(`{\rtf`)):
+		return ports.DetectedType{MIME: MimeRTF, Ext: ".rtf"}
+	case bytes.HasPrefix(head, oleMagic):
+		return ports.DetectedType{MIME: MimeDOC, Ext: ".doc"}
+	}
+	if charset, ok := sniffText(head, len(data) > sniffLen); ok {
+		if looksLikeHTML([]byte(decodeText(head, charset))) {
+			return ports.DetectedType{MIME: MimeHTML, Ext: ".html", Charset: charset}
+		}
+		return ports.DetectedType{MIME: MimeText, Ext: ".txt", Charset: charset}
+	}
+	return ports.DetectedType{MIME: MimeUnknown}
+}
+
+func (s *ContentSniffer) Compatible(declaredExt string, t ports.DetectedType) bool {
+	declaredExt = strings.ToLower(declaredExt)
+	if t.Ext == "" {
+		return false
+	}
+	if declaredExt == t.Ext {
+		return true
+	}
+	return strings.HasPrefix(t.MIME, "text/") && textExts[declaredExt]
+}
+
+// sniffZip tells OOXML and ODF packages apart from plain archives by their entries.
+func sniffZip(data []byte) ports.DetectedType {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return ports.DetectedType{MIME: MimeUnknown}
+	}
+	var contentTypes bool
+	for _, f := range zr.File {
+		switch {
+		case f.Name == "mimetype":
+			if mt := readZipEntry(f, 256); odfExts[mt] != "" {
+				return ports.DetectedType{MIME: mt, Ext: odfExts[mt]}
+			}
+		case f.Name == "[Content_Types].xml":
+			contentTypes = true
+		}
+	}
+	if contentTypes {
+		for _, f := range zr.File {
+			switch {
+			case strings.HasPrefix(f.Name, "word/"):
+				return ports.DetectedType{MIME: MimeDOCX, Ext: ".docx"}
+			case strings.HasPrefix(f.Name, "xl/"):
+				return ports.DetectedType{MIME: MimeXLSX, Ext: ".xlsx"}
+			case strings.HasPrefix(f.Name, "ppt/"):
+				return ports.DetectedType{MIME: MimePPTX, Ext: ".pptx"}
+			}
+		}
+	}
+	return ports.DetectedType{MIME: MimeZip, Ext: ".zip"}
+}
+
+func readZipEntry(f *zip.File, limit int64) string {
+	rc, err := f.Open()
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+	b, _ := io.ReadAll(io.LimitReader(rc, limit))
+	return strings.TrimSpace(string(b))
+}
+
+func looksLikeHTML(head []byte) bool {
+	h := bytes.ToLower(bytes.TrimLeft(head, " \t\r\n"))
+	return bytes.HasPrefix(h, []byte("<!doctype html")) || bytes.HasPrefix(h, []byte("<html"))
+}
+
+// sniffText accepts BOM-marked text, BOM-less UTF-16, valid UTF-8 and 8-bit
+// text without binary control bytes, and reports the encoding it found.
+// Vertical tabs and the Ctrl-Z that DOS editors write at the end of a file
+// are text too.
+func sniffText(head []byte, truncated bool) (string, bool) {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}):
+		return EncUTF8, true
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		return EncUTF16LE, true
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		return EncUTF16BE, true
+	}
+	if enc := guessUTF16(head); enc != "" {
+		return enc, true
+	}
+	for i, c := range head {
+		if c == dosEOF && i == len(head)-1 && !truncated {
+			continue
+		}
+		if c == 0 || (c < 0x20 && c != '\t' && c != '\n' && c != '\v' && c != '\r' && c != '\f') {
+			return "", false
+		}
+	}
+	if truncated {
+		head = trimPartialRune(head)
+	}
+	return detectEncoding(head), true
+}
+
+// trimPartialRune drops a multi-byte sequence cut in half by the sniff window.
+func trimPartialRune(b []byte) []byte {
+	for i := 0; i < utf8.UTFMax-1 && len(b) > 0 && !utf8.Valid(b); i++ {
+		b = b[:len(b)-1]
+	}
+	return b
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"detector_plagio/backend/internal/ports"
+)
+
+func zipWith(t *testing.T, entries ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := 0; i+1 < len(entries); i += 2 {
+		w, err := zw.Create(entries[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(entries[i+1]))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetect(t *testing.T) {
+	s := NewContentSniffer()
+	cases := []struct {
+		name    string
+		data    []byte
+		mime    string
+		ext     string
+		charset string
+	}{
+		{"empty", nil, MimeUnknown, "", ""},
+		{"pdf", []byte("%PDF-1.7\n%âãÏÓ\n1 0 obj"), MimePDF, ".pdf", ""},
+		{"pdf after whitespace", []byte("\r\n  %PDF-1.4\n"), MimePDF, ".pdf", ""},
+		{"pdf after bom", []byte("\xEF\xBB\xBF%PDF-1.4\n"), MimePDF, ".pdf", ""},
+		{"text mentioning pdf magic", []byte("Los PDF empiezan con %PDF- seguido de la versión."), MimeText, ".txt", EncUTF8},
+		{"rtf", []byte(`{\rtf1\ansi hola}`), MimeRTF, ".rtf", ""},
+		{"ole", append(append([]byte{}, oleMagic...), 0, 0, 0, 0), MimeDOC, ".doc", ""},
+		{"docx", zipWith(t, "[Content_Types].xml", "<Types/>", "word/document.xml", "<w:document/>"), MimeDOCX, ".docx", ""},
+		{"xlsx", zipWith(t, "[Content_Types].xml", "<Types/>", "xl/workbook.xml", "<workbook/>"), MimeXLSX, ".xlsx", ""},
+		{"odt", zipWith(t, "mimetype", MimeODT, "content.xml", "<office/>"), MimeODT, ".odt", ""},
+		{"plain zip", zipWith(t, "a.txt", "hola"), MimeZip, ".zip", ""},
+		{"html", []byte("  <!DOCTYPE html><html><body>hola</body></html>"), MimeHTML, ".html", EncUTF8},
+		{"utf-8 text", []byte("canción de cuna"), MimeText, ".txt", EncUTF8},
+		{"cp1252 text", []byte("\x93comillas\x94 y ca\xf1\xf3n"), MimeText, ".txt", EncCP1252},
+		{"vertical tab", []byte("uno\vdos\r\n"), MimeText, ".txt", EncUTF8},
+		{"dos eof", []byte("fin del archivo\r\n\x1a"), MimeText, ".txt", EncUTF8},
+		{"8-bit with dos eof", []byte("ca\xf1\xf3n\x1a"), MimeText, ".txt", EncLatin1},
+		{"ctrl-z mid file", []byte("uno\x1ados"), MimeUnknown, "", ""},
+		{"binary", []byte{0x01, 0x02, 0x03, 0x04, 'a'}, MimeUnknown, "", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := s.Detect(c.data)
+			if got.MIME != c.mime || got.Ext != c.ext || got.Charset != c.charset {
+				t.Errorf("Detect = %+v, want {MIME:%s Ext:%s Charset:%s}", got, c.mime, c.ext, c.charset)
+			}
+		})
+	}
+}
+
+func TestCompatible(t *testing.T) {
+	s := NewContentSniffer()
+	text := ports.DetectedType{MIME: MimeText, Ext: ".txt", Charset: EncUTF8}
+	html := ports.DetectedType{MIME: MimeHTML, Ext: ".html", Charset: EncUTF8}
+	pdf := ports.DetectedType{MIME: MimePDF, Ext: ".pdf"}
+	docx := ports.DetectedType{MIME: MimeDOCX, Ext: ".docx"}
+	unknown := ports.DetectedType{MIME: MimeUnknown}
+	cases := []struct {
+		declared string
+		t        ports.DetectedType
+		want     bool
+	}{
+		{".pdf", pdf, true},
+		{".PDF", pdf, true},
+		{".docx", docx, true},
+		{".txt", text, true},
+		{".md", text, true},
+		{".markdown", text, true},
+		{".html", text, true},
+		{".htm", html, true},
+		{".txt", html, true},
+		{".pdf", text, false},
+		{".docx", pdf, false},
+		{".txt", pdf, false},
+		{".doc", docx, false},
+		{".txt", unknown, false},
+		{"", unknown, false},
+	}
+	for _, c := range cases {
+		if got := s.Compatible(c.declared, c.t); got != c.want {
+			t.Errorf("Compatible(%q, %s) = %v, want %v", c.declared, c.t.MIME, got, c.want)
+		}
+	}
+}
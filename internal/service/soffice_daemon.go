@@ -0,0 +1,246 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"detector_plagio/backend/internal/ports"
+)
+
+// ErrDaemonUnavailable is returned when no conversion daemon is healthy.
+var ErrDaemonUnavailable = errors.New("soffice daemon unavailable")
+
+const (
+	daemonStartTimeout   = 60 * time.Second
+	daemonHealthInterval = 15 * time.Second
+	daemonHealthFailures = 3
+	daemonMaxBackoff     = time.Minute
+)
+
+// sofficeDaemon is one supervised unoserver process: a headless soffice
+// listener that keeps LibreOffice loaded between conversions.
+type sofficeDaemon struct {
+	id         int
+	port       int
+	unoPort    int
+	profileDir string
+	healthy    atomic.Bool
+}
+
+func (d *sofficeDaemon) addr() string { return net.JoinHostPort("127.0.0.1", strconv.Itoa(d.port)) }
+
+// SofficeDaemonPool runs a small set of long-lived soffice listeners and
+// queues conversions onto them. Callers fall back to one-shot conversion
+// when Convert reports ErrDaemonUnavailable.
+type SofficeDaemonPool struct {
+	baseDir string
+	daemons []*sofficeDaemon
+	idle    chan *sofficeDaemon
+	stop    context.CancelFunc
+	done    chan struct{}
+}
+
+// NewSofficeDaemonPool prepares size daemons listening on consecutive port
+// pairs starting at basePort. Nothing runs until Start is called.
+func NewSofficeDaemonPool(size, basePort int) (*SofficeDaemonPool, error) {
+	base, err := os.MkdirTemp("", "docsim-unoserver-")
+	if err != nil {
+		return nil, err
+	}
+	p := &SofficeDaemonPool{baseDir: base, idle: make(chan *sofficeDaemon, size), done: make(chan struct{})}
+	for i := 0; i < size; i++ {
+		d := &sofficeDaemon{
+			id:         i,
+			port:       basePort + 2*i,
+			unoPort:    basePort + 2*i + 1,
+			profileDir: filepath.Join(base, fmt.Sprintf("profile-%d", i)),
+		}
+		p.daemons = append(p.daemons, d)
+		p.idle <- d
+	}
+	return p, nil
+}
+
+// Start launches and supervises every daemon until Stop is called.
+func (p *SofficeDaemonPool) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.stop = cancel
+	remaining := int32(len(p.daemons))
+	for _, d := range p.daemons {
+		go func(d *sofficeDaemon) {
+			p.supervise(ctx, d)
+			if atomic.AddInt32(&remaining, -1) == 0 {
+				close(p.done)
+			}
+		}(d)
+	}
+	if len(p.daemons) == 0 {
+		close(p.done)
+	}
+}
+
+// Stop kills the daemons, waits for their supervisors and removes their profiles.
+func (p *SofficeDaemonPool) Stop() {
+	if p.stop != nil {
+		p.stop()
+		<-p.done
+	}
+	_ = os.RemoveAll(p.baseDir)
+}
+
+// Available reports whether at least one daemon passed its last health check.
+func (p *SofficeDaemonPool) Available() bool {
+	for _, d := range p.daemons {
+		if d.healthy.Load() {
+			return true
+		}
+	}
+	return false
+}
+
+// Convert waits for an idle healthy daemon and converts inputPath with it.
+// filter uses soffice's "ext:FilterName" syntax.
+func (p *SofficeDaemonPool) Convert(ctx context.Context, inputPath, filter string) ([]byte, error) {
+	if !p.Available() {
+		return nil, ErrDaemonUnavailable
+	}
+	var d *sofficeDaemon
+	for tries := 0; tries <= len(p.daemons); tries++ {
+		select {
+		case d = <-p.idle:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for soffice daemon: %w", ctx.Err())
+		}
+		if d.healthy.Load() {
+			break
+		}
+		p.idle <- d
+		d = nil
+	}
+	if d == nil {
+		return nil, ErrDaemonUnavailable
+	}
+	defer func() { p.idle <- d }()
+
+	ext, filterName, _ := strings.Cut(filter, ":")
+	args := []string{"--host", "127.0.0.1", "--port", strconv.Itoa(d.port), "--convert-to", ext}
+	if filterName != "" {
+		args = append(args, "--filter", filterName)
+	}
+	cmd := commandContext(ctx, "unoconvert", append(args, inputPath, "-")...)
+	var out, errb bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errb
+	log.Printf("SofficeDaemonPool: daemon %d converting %s", d.id, inputPath)
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, &ports.ToolError{Tool: "unoconvert", Err: ctx.Err()}
+		}
+		return nil, &ports.ToolError{Tool: "unoconvert", Err: err, Stderr: strings.TrimSpace(errb.String())}
+	}
+	return out.Bytes(), nil
+}
+
+// supervise keeps one daemon running: it restarts the process with
+// exponential backoff when it exits or stops answering health checks.
+func (p *SofficeDaemonPool) supervise(ctx context.Context, d *sofficeDaemon) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		started := time.Now()
+		p.runOnce(ctx, d)
+		d.healthy.Store(false)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > daemonMaxBackoff {
+			backoff = time.Second
+		}
+		log.Printf("SofficeDaemonPool: daemon %d stopped, restarting in %s", d.id, backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(backoff*2, daemonMaxBackoff)
+	}
+}
+
+func (p *SofficeDaemonPool) runOnce(ctx context.Context, d *sofficeDaemon) {
+	runCtx, kill := context.WithCancel(ctx)
+	defer kill()
+	cmd := commandContext(runCtx, "unoserver",
+		"--interface", "127.0.0.1",
+		"--port", strconv.Itoa(d.port),
+		"--uno-port", strconv.Itoa(d.unoPort),
+		"--user-installation", d.profileDir)
+	var errb bytes.Buffer
+	cmd.Stderr = &errb
+	if err := cmd.Start(); err != nil {
+		log.Printf("SofficeDaemonPool: daemon %d failed to start: %v", d.id, err)
+		return
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	deadline := time.Now().Add(daemonStartTimeout)
+	for !probe(d.addr()) {
+		if time.Now().After(deadline) {
+			log.Printf("SofficeDaemonPool: daemon %d not listening after %s", d.id, daemonStartTimeout)
+			kill()
+			<-exited
+			return
+		}
+		select {
+		case err := <-exited:
+			log.Printf("SofficeDaemonPool: daemon %d exited during startup: %v, stderr: %s", d.id, err, errb.String())
+			return
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+	d.healthy.Store(true)
+	log.Printf("SofficeDaemonPool: daemon %d listening on %s", d.id, d.addr())
+
+	ticker := time.NewTicker(daemonHealthInterval)
+	defer ticker.Stop()
+	failures := 0
+	for {
+		select {
+		case err := <-exited:
+			log.Printf("SofficeDaemonPool: daemon %d exited: %v", d.id, err)
+			return
+		case <-ticker.C:
+			if probe(d.addr()) {
+				failures = 0
+				d.healthy.Store(true)
+				continue
+			}
+			failures++
+			d.healthy.Store(false)
+			if failures >= daemonHealthFailures {
+				log.Printf("SofficeDaemonPool: daemon %d failed %d health checks, killing", d.id, failures)
+				kill()
+				<-exited
+				return
+			}
+		}
+	}
+}
+
+func probe(addr string) bool {
+	conn, err := net.DialTimeout("tcp", addr, 2*time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
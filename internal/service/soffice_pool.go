@@ -0,0 +1,91 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"detector_plagio/backend/internal/ports"
+)
+
+// sofficeWorker owns a private LibreOffice profile and output directory.
+// soffice locks its profile, so two conversions sharing one fail at random.
+type sofficeWorker struct {
+	id         int
+	profileDir string
+	outDir     string
+}
+
+// SofficePool limits concurrent soffice runs to the number of workers and
+// gives each run an isolated -env:UserInstallation profile.
+type SofficePool struct {
+	baseDir string
+	workers chan *sofficeWorker
+}
+
+func NewSofficePool(size int) (*SofficePool, error) {
+	if size < 1 {
+		size = 1
+	}
+	base, err := os.MkdirTemp("", "docsim-soffice-")
+	if err != nil {
+		return nil, err
+	}
+	p := &SofficePool{baseDir: base, workers: make(chan *sofficeWorker, size)}
+	for i := 0; i < size; i++ {
+		w := &sofficeWorker{
+			id:         i,
+			profileDir: filepath.Join(base, fmt.Sprintf("profile-%d", i)),
+			outDir:     filepath.Join(base, fmt.Sprintf("out-%d", i)),
+		}
+		if err := os.MkdirAll(w.outDir, 0700); err != nil {
+			return nil, err
+		}
+		p.workers <- w
+	}
+	log.Printf("SofficePool: %d workers under %s", size, base)
+	return p, nil
+}
+
+// Convert runs "soffice --convert-to filter" on inputPath and returns the
+// converted file. It waits for a free worker unless ctx ends first.
+func (p *SofficePool) Convert(ctx context.Context, inputPath, filter string) ([]byte, error) {
+	var w *sofficeWorker
+	select {
+	case w = <-p.workers:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for soffice worker: %w", ctx.Err())
+	}
+	defer func() { p.workers <- w }()
+
+	profile := (&url.URL{Scheme: "file", Path: w.profileDir}).String()
+	cmd := commandContext(ctx, "soffice", "-env:UserInstallation="+profile, "--headless", "--norestore",
+		"--convert-to", filter, "--outdir", w.outDir, inputPath)
+	var errb bytes.Buffer
+	cmd.Stderr = &errb
+	log.Printf("SofficePool: worker %d running %v", w.id, cmd.Args)
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, &ports.ToolError{Tool: "soffice", Err: ctx.Err()}
+		}
+		return nil, &ports.ToolError{Tool: "soffice", Err: err, Stderr: strings.TrimSpace(errb.String())}
+	}
+
+	ext := filter
+	if i := strings.IndexByte(ext, ':'); i >= 0 {
+		ext = ext[:i]
+	}
+	outPath := filepath.Join(w.outDir, strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))+"."+ext)
+	defer os.Remove(outPath)
+	return os.ReadFile(outPath)
+}
+
+// Close removes the worker profiles. Conversions still running will fail.
+func (p *SofficePool) Close() error {
+	return os.RemoveAll(p.baseDir)
+}
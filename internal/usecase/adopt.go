@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+// Reasons a stored file needs adopting, and what adopting it does.
+const (
+	OrphanMissingSidecar = "missing-sidecar"
+	OrphanMissingText    = "missing-text"
+
+	AdoptReindex   = "reindex"
+	AdoptWriteText = "write-text"
+
+	AdoptPlanned = "planned"
+	AdoptDone    = "adopted"
+	AdoptFailed  = "failed"
+)
+
+type AdoptEntry struct {
+	ID      string   `json:"id"`
+	File    string   `json:"file"`
+	Reasons []string `json:"reasons"`
+	Action  string   `json:"action"`
+	Status  string   `json:"status"`
+	Error   string   `json:"error,omitempty"`
+}
+
+type AdoptReport struct {
+	DryRun  bool         `json:"dryRun"`
+	Scanned int          `json:"scanned"`
+	Entries []AdoptEntry `json:"entries"`
+	Adopted int          `json:"adopted"`
+	Failed  int          `json:"failed"`
+}
+
+// Adopt repairs raw files that List shows but that never match anything
+// because their sidecar or their texts/<id>.txt is missing.
+type Adopt struct {
+	repo   ports.DocumentRepo
+	ingest *Ingest
+}
+
+func NewAdopt(repo ports.DocumentRepo, ingest *Ingest) *Adopt {
+	return &Adopt{repo: repo, ingest: ingest}
+}
+
+// Run finds the orphans and, unless dryRun, adopts them. A file without a
+// sidecar is ingested again under its ID, which backfills the text, hash and
+// metadata; a sidecar that still has its text only gets the text file back.
+func (u *Adopt) Run(ctx context.Context, dryRun bool) (AdoptReport, error) {
+	report := AdoptReport{DryRun: dryRun, Entries: []AdoptEntry{}}
+	ids, err := u.repo.ListIDs()
+	if err != nil {
+		return report, err
+	}
+	sort.Strings(ids)
+	report.Scanned = len(ids)
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		rawPath, txtPath := u.repo.PathFor(id)
+		doc, docErr := u.repo.Get(id)
+		_, txtErr := os.Stat(txtPath)
+		entry := AdoptEntry{ID: id, File: filepath.Base(rawPath), Action: AdoptReindex, Status: AdoptPlanned}
+		if docErr != nil {
+			entry.Reasons = append(entry.Reasons, OrphanMissingSidecar)
+			doc = domain.Document{ID: id, OriginalFilename: entry.File}
+		}
+		if txtErr != nil {
+			entry.Reasons = append(entry.Reasons, OrphanMissingText)
+		}
+		if len(entry.Reasons) == 0 {
+			continue
+		}
+		if docErr == nil && doc.TextContent != "" {
+			entry.Action = AdoptWriteText
+		}
+		if !dryRun {
+			if err := u.adopt(ctx, entry.Action, doc, rawPath, txtPath); err != nil {
+				log.Printf("Adopt: %s failed: %v", id, err)
+				entry.Status, entry.Error = AdoptFailed, err.Error()
+				report.Failed++
+			} else {
+				entry.Status = AdoptDone
+				report.Adopted++
+			}
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+	log.Printf("Adopt: scanned=%d orphans=%d adopted=%d failed=%d dryRun=%v", report.Scanned, len(report.Entries), report.Adopted, report.Failed, dryRun)
+	return report, nil
+}
+
+func (u *Adopt) adopt(ctx context.Context, action string, doc domain.Document, rawPath, txtPath string) error {
+	if action == AdoptWriteText {
+		return os.WriteFile(txtPath, []byte(doc.TextContent), 0644)
+	}
+	data, err := os.ReadFile(rawPath)
+	if err != nil {
+		return err
+	}
+	_, err = u.ingest.SaveAndIndex(ctx, domain.Document{ID: doc.ID, Folder: doc.Folder, OriginalFilename: doc.OriginalFilename, Owner: doc.Owner, Role: doc.Role}, data)
+	return err
+}
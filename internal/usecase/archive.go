@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"detector_plagio/backend/internal/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	EntryCreated   = "created"
+	EntryDuplicate = "duplicate"
+	EntrySkipped   = "skipped"
+	EntryFailed    = "failed"
+)
+
+// nestedArchiveExts are never unpacked recursively; they are reported as skipped.
+var nestedArchiveExts = map[string]bool{".zip": true, ".rar": true, ".7z": true, ".tar": true, ".gz": true, ".tgz": true, ".bz2": true, ".xz": true}
+
+type ArchiveEntry struct {
+	Path        string `json:"path"`
+	Status      string `json:"status"`
+	ID          string `json:"id,omitempty"`
+	Folder      string `json:"folder,omitempty"`
+	DuplicateOf string `json:"duplicateOf,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+type ArchiveReport struct {
+	Entries    []ArchiveEntry `json:"entries"`
+	Created    int            `json:"created"`
+	Duplicates int            `json:"duplicates"`
+	Skipped    int            `json:"skipped"`
+	Failed     int            `json:"failed"`
+}
+
+func (r *ArchiveReport) add(e ArchiveEntry) {
+	switch e.Status {
+	case EntryCreated:
+		r.Created++
+	case EntryDuplicate:
+		r.Duplicates++
+	case EntrySkipped:
+		r.Skipped++
+	case EntryFailed:
+		r.Failed++
+	}
+	r.Entries = append(r.Entries, e)
+}
+
+// IngestArchive ingests every supported file of a ZIP, placing each one in
+// base.Folder joined with the entry's directory inside the archive. Every
+// document takes its owner and role from base. Files already in the corpus
+// (same SHA-256) are reported as duplicates.
+func (u *Ingest) IngestArchive(ctx context.Context, base domain.Document, r io.ReaderAt, size int64) (ArchiveReport, error) {
+	var report ArchiveReport
+	if !domain.ValidRole(base.Role) {
+		return report, fmt.Errorf("unknown role %q", base.Role)
+	}
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return report, err
+	}
+	if len(zr.File) > u.cfg.ArchiveMaxEntries {
+		return report, fmt.Errorf("archive has %d entries, limit is %d", len(zr.File), u.cfg.ArchiveMaxEntries)
+	}
+	known, err := u.checksums()
+	if err != nil {
+		return report, err
+	}
+
+	maxEntry := uint64(u.cfg.MaxUploadMB) << 20
+	budget := uint64(u.cfg.ArchiveMaxTotalMB) << 20
+	for _, f := range zr.File {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		entry := ArchiveEntry{Path: f.Name}
+		name, ok := safeEntryPath(f.Name)
+		if !ok {
+			entry.Status, entry.Reason = EntrySkipped, "unsafe path"
+			report.add(entry)
+			continue
+		}
+		filename := path.Base(name)
+		ext := strings.ToLower(path.Ext(filename))
+		switch {
+		case strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(filename, "."):
+			continue
+		case nestedArchiveExts[ext]:
+			entry.Status, entry.Reason = EntrySkipped, "nested archive"
+			report.add(entry)
+			continue
+		case ext != "" && !u.cfg.AllowedExtMap[ext]:
+			entry.Status, entry.Reason = EntrySkipped, "unsupported extension "+ext
+			report.add(entry)
+			continue
+		case f.UncompressedSize64 > maxEntry:
+			entry.Status, entry.Reason = EntrySkipped, "entry exceeds upload size limit"
+			report.add(entry)
+			continue
+		case f.UncompressedSize64 > 1<<20 && f.UncompressedSize64 > f.CompressedSize64*uint64(u.cfg.ArchiveMaxRatio):
+			entry.Status, entry.Reason = EntrySkipped, "suspicious compression ratio"
+			report.add(entry)
+			continue
+		}
+
+		limit := min(maxEntry, budget)
+		data, err := readEntry(f, limit)
+		if err != nil {
+			if errors.Is(err, errEntryTooLarge) && limit == budget {
+				return report, errors.New("archive expands beyond the total size limit")
+			}
+			entry.Status, entry.Reason = EntryFailed, err.Error()
+			report.add(entry)
+			continue
+		}
+		budget -= uint64(len(data))
+
+		if d := u.detector.Detect(data); d.Ext == ".zip" {
+			entry.Status, entry.Reason = EntrySkipped, "nested archive"
+			report.add(entry)
+			continue
+		}
+		sum := checksum(data)
+		if id, dup := known[sum]; dup {
+			entry.Status, entry.DuplicateOf = EntryDuplicate, id
+			report.add(entry)
+			continue
+		}
+
+		entry.Folder = entryFolder(base.Folder, name)
+		doc, err := u.SaveAndIndex(ctx, domain.Document{ID: uuid.NewString(), Folder: entry.Folder, OriginalFilename: filename, Owner: base.Owner, OwnerVerified: base.OwnerVerified, Role: base.Role}, data)
+		if err != nil {
+			log.Printf("IngestArchive: %s failed: %v", f.Name, err)
+			entry.Status, entry.Reason = EntryFailed, err.Error()
+			report.add(entry)
+			continue
+		}
+		known[sum] = doc.ID
+		entry.Status, entry.ID = EntryCreated, doc.ID
+		report.add(entry)
+	}
+	log.Printf("IngestArchive: created=%d duplicates=%d skipped=%d failed=%d", report.Created, report.Duplicates, report.Skipped, report.Failed)
+	return report, nil
+}
+
+// checksums maps the SHA-256 of every stored upload to its document ID.
+// Documents stored before the hash was recorded get it backfilled from
+// their raw file, so they are caught as duplicates too.
+func (u *Ingest) checksums() (map[string]string, error) {
+	docs, err := u.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, len(docs))
+	for _, d := range docs {
+		if d.SHA256 == "" {
+			if d.SHA256 = u.backfillChecksum(d); d.SHA256 == "" {
+				continue
+			}
+		}
+		m[d.SHA256] = d.ID
+	}
+	return m, nil
+}
+
+// backfillChecksum hashes the stored raw file of d and records the result in
+// its sidecar. It returns "" when the file cannot be read.
+func (u *Ingest) backfillChecksum(d domain.Document) string {
+	raw, _ := u.repo.PathFor(d.ID)
+	f, err := os.Open(raw)
+	if err != nil {
+		log.Printf("IngestArchive: no raw file to hash for %s: %v", d.ID, err)
+		return ""
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		log.Printf("IngestArchive: hashing %s: %v", d.ID, err)
+		return ""
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	if _, err := u.repo.Update(d.ID, func(doc *domain.Document) error {
+		doc.SHA256 = sum
+		return nil
+	}); err != nil {
+		log.Printf("IngestArchive: saving checksum of %s: %v", d.ID, err)
+	}
+	return sum
+}
+
+var errEntryTooLarge = errors.New("entry expands beyond its size limit")
+
+// readEntry decompresses at most limit bytes; the header sizes are not
+// trusted because a crafted archive can lie about them.
+func readEntry(f *zip.File, limit uint64) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(data)) > limit {
+		return data, errEntryTooLarge
+	}
+	return data, nil
+}
+
+// safeEntryPath rejects absolute paths and parent traversal (zip-slip) and
+// returns the cleaned slash-separated name.
+func safeEntryPath(name string) (string, bool) {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, ":") {
+		return "", false
+	}
+	clean := path.Clean(name)
+	for _, part := range strings.Split(clean, "/") {
+		if part == ".." {
+			return "", false
+		}
+	}
+	return clean, true
+}
+
+func entryFolder(baseFolder, name string) string {
+	dir := path.Dir(name)
+	if dir == "." {
+		return baseFolder
+	}
+	if baseFolder == "" {
+		return dir
+	}
+	return baseFolder + "/" + dir
+}
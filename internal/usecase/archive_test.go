@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"os"
+	"testing"
+
+	"detector_plagio/backend/internal/config"
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/repo"
+)
+
+func TestChecksumsBackfillLegacyDocuments(t *testing.T) {
+	cfg := &config.Config{DataRoot: t.TempDir(), AllowedExtMap: map[string]bool{".txt": true}}
+	if err := os.MkdirAll(cfg.DocsPath(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	r := repo.NewFSRepo(cfg)
+	data := []byte("texto subido antes de guardar el hash")
+	if err := r.Save(domain.Document{ID: "legacy-doc", Ext: ".txt", Folder: "a"}, data); err != nil {
+		t.Fatal(err)
+	}
+
+	known, err := (&Ingest{repo: r}).checksums()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := checksum(data)
+	if known[sum] != "legacy-doc" {
+		t.Fatalf("checksums = %v, want %s -> legacy-doc", known, sum)
+	}
+	if d, err := r.Get("legacy-doc"); err != nil || d.SHA256 != sum || d.Folder != "a" {
+		t.Errorf("sidecar after backfill = %+v, %v", d, err)
+	}
+}
@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"detector_plagio/backend/internal/config"
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+	"github.com/google/uuid"
+)
+
+var ErrCheckNotFound = errors.New("check not found")
+
+type CheckMatch struct {
+	ID               string                  `json:"id"`
+	Folder           string                  `json:"folder"`
+	OriginalFilename string                  `json:"originalFilename"`
+	Role             string                  `json:"role"`
+	Final            int                     `json:"finalPercent"`
+	Near             int                     `json:"nearDuplicatePercent"`
+	Topic            int                     `json:"topicSimilarityPercent"`
+	MatchingSegments []ports.MatchingSegment `json:"matchingSegments"`
+}
+
+// CheckResult describes the checked file as it would have been ingested and
+// the stored documents most similar to it.
+type CheckResult struct {
+	ID               string            `json:"id"`
+	OriginalFilename string            `json:"originalFilename"`
+	Ext              string            `json:"ext"`
+	MimeType         string            `json:"mimeType"`
+	Size             int64             `json:"size"`
+	TextLength       int               `json:"textLength"`
+	Integrity        *domain.Integrity `json:"integrity,omitempty"`
+	TextStats        *domain.TextStats `json:"textStats,omitempty"`
+	Compared         int               `json:"compared"`
+	Matches          []CheckMatch      `json:"matches"`
+	CreatedAt        time.Time         `json:"createdAt"`
+	// ExpiresAt is set when the report is kept and can be fetched again.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Check ranks a file against the corpus without adding it: the upload is
+// extracted in memory, like Ingest.Process, and dropped afterwards. With a
+// retention configured the report (never the file) is kept for that long.
+type Check struct {
+	cfg     *config.Config
+	repo    ports.DocumentRepo
+	ingest  *Ingest
+	compare *Compare
+}
+
+func NewCheck(cfg *config.Config, repo ports.DocumentRepo, ingest *Ingest, compare *Compare) *Check {
+	return &Check{cfg: cfg, repo: repo, ingest: ingest, compare: compare}
+}
+
+// Run compares the file against the stored documents with one of roles, in
+// one of folders when given, and returns the topK best matches with their
+// segments.
+func (u *Check) Run(ctx context.Context, filename string, data []byte, folders []string, roles map[string]bool, topK int) (CheckResult, error) {
+	doc, err := u.ingest.Process(ctx, domain.Document{ID: uuid.NewString(), OriginalFilename: filename}, data)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	res := CheckResult{
+		ID: doc.ID, OriginalFilename: filename, Ext: doc.Ext, MimeType: doc.MimeType, Size: doc.Size,
+		TextLength: len(doc.TextContent), Integrity: doc.Integrity, TextStats: doc.TextStats,
+		Matches: []CheckMatch{}, CreatedAt: time.Now().UTC(),
+	}
+
+	docs, err := u.repo.List()
+	if err != nil {
+		return res, err
+	}
+	allowed := map[string]bool{}
+	for _, f := range folders {
+		allowed[f] = true
+	}
+	for _, other := range docs {
+		if (len(allowed) > 0 && !allowed[other.Folder]) || !roles[other.CorpusRole()] {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		cmp, err := u.compare.CompareDocs(doc, other)
+		if err != nil {
+			continue
+		}
+		res.Compared++
+		res.Matches = append(res.Matches, CheckMatch{
+			ID: other.ID, Folder: other.Folder, OriginalFilename: other.OriginalFilename, Role: other.CorpusRole(),
+			Final:            int(cmp.Final*100 + 0.5),
+			Near:             int(cmp.NearDuplicate*100 + 0.5),
+			Topic:            int(cmp.TopicSimilarity*100 + 0.5),
+			MatchingSegments: cmp.MatchingSegments,
+		})
+	}
+	sort.SliceStable(res.Matches, func(i, j int) bool { return res.Matches[i].Final > res.Matches[j].Final })
+	if len(res.Matches) > topK {
+		res.Matches = res.Matches[:topK]
+	}
+
+	if u.cfg.CheckRetention > 0 {
+		exp := res.CreatedAt.Add(u.cfg.CheckRetention)
+		res.ExpiresAt = &exp
+		if err := u.save(res); err != nil {
+			log.Printf("Check: keeping report %s: %v", res.ID, err)
+			res.ExpiresAt = nil
+		}
+	}
+	return res, nil
+}
+
+// Get returns a kept report that has not expired yet.
+func (u *Check) Get(id string) (CheckResult, error) {
+	var res CheckResult
+	if uuid.Validate(id) != nil {
+		return res, ErrCheckNotFound
+	}
+	b, err := os.ReadFile(u.path(id))
+	if err != nil {
+		return res, ErrCheckNotFound
+	}
+	if err := json.Unmarshal(b, &res); err != nil {
+		return res, err
+	}
+	if res.ExpiresAt == nil || time.Now().After(*res.ExpiresAt) {
+		return CheckResult{}, ErrCheckNotFound
+	}
+	return res, nil
+}
+
+// PurgeExpired deletes the kept reports past their expiry.
+func (u *Check) PurgeExpired() (int, error) {
+	entries, err := os.ReadDir(u.cfg.ChecksPath())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, e := range entries {
+		path := filepath.Join(u.cfg.ChecksPath(), e.Name())
+		var res CheckResult
+		b, err := os.ReadFile(path)
+		if err == nil {
+			err = json.Unmarshal(b, &res)
+		}
+		if err == nil && res.ExpiresAt != nil && time.Now().Before(*res.ExpiresAt) {
+			continue
+		}
+		if err := os.Remove(path); err == nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// RunJanitor purges expired reports every interval until ctx is done.
+func (u *Check) RunJanitor(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if n, err := u.PurgeExpired(); err != nil {
+				log.Printf("Check: janitor: %v", err)
+			} else if n > 0 {
+				log.Printf("Check: janitor purged %d expired reports", n)
+			}
+		}
+	}
+}
+
+func (u *Check) path(id string) string { return filepath.Join(u.cfg.ChecksPath(), id+".json") }
+
+func (u *Check) save(res CheckResult) error {
+	if err := os.MkdirAll(u.cfg.ChecksPath(), 0755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(u.path(res.ID), b, 0600)
+}
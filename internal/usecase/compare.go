@@ -3,9 +3,13 @@ package usecase
 import (
 	"log"
 
+	"detector_plagio/backend/internal/domain"
 	"detector_plagio/backend/internal/ports"
 )
 
+// shingleSize is the number of tokens per shingle used to detect copied passages.
+const shingleSize = 5
+
 type Compare struct {
 	repo ports.DocumentRepo
 	norm ports.Normalizer
@@ -40,20 +44,20 @@ func (u *Compare) CompareTwo(id1, id2 string) (CompareResult, error) {
 	if err != nil {
 		return CompareResult{}, err
 	}
+	return u.CompareDocs(doc1, doc2)
+}
 
+// CompareDocs compares two documents that need not be stored.
+func (u *Compare) CompareDocs(doc1, doc2 domain.Document) (CompareResult, error) {
 	log.Printf("Doc1 TextContent length: %d, Doc2 TextContent length: %d", len(doc1.TextContent), len(doc2.TextContent))
-	tok1 := u.norm.Tokenize(doc1.TextContent)
-	tok2 := u.norm.Tokenize(doc2.TextContent)
+	f1, f2 := u.features(doc1), u.features(doc2)
+	tok1, tok2, sh1, sh2 := f1.tokens, f2.tokens, f1.shingles, f2.shingles
 	log.Printf("Doc1 tokens length: %d, Doc2 tokens length: %d", len(tok1), len(tok2))
-	sh1 := u.norm.Shingles(tok1, 5)
-	sh2 := u.norm.Shingles(tok2, 5)
 	log.Printf("Doc1 shingles length: %d, Doc2 shingles length: %d", len(sh1), len(sh2))
 
-	jaccardResult := u.sim.Jaccard(sh1, sh2)
-	cosineResult := u.sim.CosineTFIDF(tok1, tok2)
+	jaccardResult, cosineResult, final := u.scores(f1, f2)
 	near := jaccardResult.Score
 	topic := cosineResult.Score
-	final := 0.6*near + 0.4*topic
 	log.Printf("Near: %f, Topic: %f, Final: %f", near, topic, final)
 
 	matchingSegments, err := u.sim.CompareSegments(doc1.TextContent, doc2.TextContent)
@@ -77,3 +81,33 @@ func (u *Compare) CompareTwo(id1, id2 string) (CompareResult, error) {
 		MatchingSegments:      matchingSegments,
 	}, nil
 }
+
+// docFeatures are the per-document inputs of the scores, so that a batch
+// can compute them once per document instead of once per pair.
+type docFeatures struct {
+	tokens, shingles []string
+	shingleSet       map[string]bool
+	termCounts       map[string]int
+}
+
+func (u *Compare) features(doc domain.Document) docFeatures {
+	tok := u.norm.Tokenize(doc.TextContent)
+	f := docFeatures{tokens: tok, shingles: u.norm.Shingles(tok, shingleSize), termCounts: make(map[string]int, len(tok))}
+	f.shingleSet = make(map[string]bool, len(f.shingles))
+	for _, s := range f.shingles {
+		f.shingleSet[s] = true
+	}
+	for _, t := range tok {
+		f.termCounts[t]++
+	}
+	return f
+}
+
+// scores returns the near-duplicate (Jaccard over shingles) and topic
+// (TF-IDF cosine over tokens) results and the final score weighting them.
+// It logs nothing, as batches call it for every pair.
+func (u *Compare) scores(f1, f2 docFeatures) (ports.JaccardResult, ports.CosineTFIDFResult, float64) {
+	j := u.sim.JaccardSets(f1.shingleSet, f2.shingleSet)
+	c := u.sim.CosineCounts(f1.termCounts, f2.termCounts)
+	return j, c, 0.6*j.Score + 0.4*c.Score
+}
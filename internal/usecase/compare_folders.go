@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+
+	"detector_plagio/backend/internal/domain"
+)
+
+// MaxFolderPairs bounds a folder comparison.
+const MaxFolderPairs = 250000
+
+type FolderMatch struct {
+	ID               string  `json:"id"`
+	Folder           string  `json:"folder"`
+	OriginalFilename string  `json:"originalFilename"`
+	Role             string  `json:"role"`
+	Final            float64 `json:"final"`
+	Near             float64 `json:"near"`
+	Topic            float64 `json:"topic"`
+}
+
+// SourceMatches lists the best matches of one source document.
+type SourceMatches struct {
+	ID               string        `json:"id"`
+	OriginalFilename string        `json:"originalFilename"`
+	BestFinal        float64       `json:"bestFinal"`
+	Matches          []FolderMatch `json:"matches"`
+}
+
+// FolderStats aggregates the pairs against one compared folder. Flagged
+// counts the source documents whose best match in the folder reaches the
+// threshold of the request.
+type FolderStats struct {
+	Folder    string  `json:"folder"`
+	Documents int     `json:"documents"`
+	Pairs     int     `json:"pairs"`
+	MeanFinal float64 `json:"meanFinal"`
+	MaxFinal  float64 `json:"maxFinal"`
+	Flagged   int     `json:"flagged"`
+}
+
+type FolderComparison struct {
+	Source     string          `json:"source"`
+	References []string        `json:"references"`
+	Siblings   bool            `json:"siblings"`
+	Threshold  float64         `json:"threshold"`
+	Sources    []SourceMatches `json:"sources"`
+	Folders    []FolderStats   `json:"folders"`
+}
+
+// FolderRequest selects what CompareFolders compares: every submission of
+// Source against every document of References and, with Siblings, against
+// the other documents of Source. References are never compared among
+// themselves; excluded documents are left out.
+type FolderRequest struct {
+	Source     string   `json:"source"`
+	References []string `json:"references"`
+	Siblings   bool     `json:"siblings"`
+	TopK       int      `json:"topK"`
+	Threshold  float64  `json:"threshold"`
+}
+
+func (u *Compare) CompareFolders(ctx context.Context, req FolderRequest) (FolderComparison, error) {
+	if req.Source == "" || (len(req.References) == 0 && !req.Siblings) {
+		return FolderComparison{}, errors.New("source and at least one reference folder (or siblings) required")
+	}
+	all, err := u.repo.List()
+	if err != nil {
+		return FolderComparison{}, err
+	}
+	// references are never checked and excluded documents never compared
+	byFolder := map[string][]domain.Document{}
+	var sources []domain.Document
+	for _, d := range all {
+		if d.CorpusRole() == domain.RoleExcluded {
+			continue
+		}
+		byFolder[d.Folder] = append(byFolder[d.Folder], d)
+		if d.Folder == req.Source && d.CorpusRole() == domain.RoleSubmission {
+			sources = append(sources, d)
+		}
+	}
+	if len(sources) == 0 {
+		return FolderComparison{}, fmt.Errorf("folder %q has no submissions", req.Source)
+	}
+	var refs []domain.Document
+	compared := []string{}
+	seen := map[string]bool{req.Source: true} // the source is covered by Siblings
+	for _, f := range req.References {
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+		refs = append(refs, byFolder[f]...)
+		compared = append(compared, f)
+	}
+	if req.Siblings {
+		refs = append(refs, byFolder[req.Source]...)
+		compared = append(compared, req.Source)
+	}
+	if len(sources)*len(refs) > MaxFolderPairs {
+		return FolderComparison{}, fmt.Errorf("%d pairs exceed the limit of %d", len(sources)*len(refs), MaxFolderPairs)
+	}
+
+	feats := map[string]docFeatures{}
+	for _, list := range [][]domain.Document{sources, refs} {
+		for _, d := range list {
+			if _, ok := feats[d.ID]; !ok {
+				feats[d.ID] = u.features(d)
+			}
+		}
+	}
+	// pairs[i] holds every pair of sources[i]; the top K are kept afterwards
+	pairs := make([][]FolderMatch, len(sources))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				src := sources[i]
+				for _, ref := range refs {
+					if ref.ID == src.ID {
+						continue
+					}
+					jac, cos, final := u.scores(feats[src.ID], feats[ref.ID])
+					pairs[i] = append(pairs[i], FolderMatch{ID: ref.ID, Folder: ref.Folder, OriginalFilename: ref.OriginalFilename, Role: ref.CorpusRole(), Final: final, Near: jac.Score, Topic: cos.Score})
+				}
+			}
+		}()
+	}
+	for i := 0; i < len(sources) && ctx.Err() == nil; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return FolderComparison{}, err
+	}
+
+	res := FolderComparison{Source: req.Source, References: compared, Siblings: req.Siblings, Threshold: req.Threshold, Sources: make([]SourceMatches, len(sources)), Folders: []FolderStats{}}
+	stats := map[string]*FolderStats{}
+	for _, f := range compared {
+		stats[f] = &FolderStats{Folder: f, Documents: len(byFolder[f])}
+	}
+	for i, src := range sources {
+		best := map[string]float64{}
+		for _, m := range pairs[i] {
+			st := stats[m.Folder]
+			st.Pairs++
+			st.MeanFinal += m.Final
+			st.MaxFinal = max(st.MaxFinal, m.Final)
+			best[m.Folder] = max(best[m.Folder], m.Final)
+		}
+		for f, b := range best {
+			if b >= req.Threshold {
+				stats[f].Flagged++
+			}
+		}
+		ms := pairs[i]
+		if ms == nil {
+			ms = []FolderMatch{}
+		}
+		sort.SliceStable(ms, func(a, b int) bool { return ms[a].Final > ms[b].Final })
+		if len(ms) > req.TopK {
+			ms = ms[:req.TopK]
+		}
+		sm := SourceMatches{ID: src.ID, OriginalFilename: src.OriginalFilename, Matches: ms}
+		if len(ms) > 0 {
+			sm.BestFinal = ms[0].Final
+		}
+		res.Sources[i] = sm
+	}
+	sort.SliceStable(res.Sources, func(a, b int) bool { return res.Sources[a].BestFinal > res.Sources[b].BestFinal })
+	for _, f := range compared {
+		st := stats[f]
+		if st.Pairs > 0 {
+			st.MeanFinal /= float64(st.Pairs)
+		}
+		res.Folders = append(res.Folders, *st)
+	}
+	return res, nil
+}
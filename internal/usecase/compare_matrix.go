@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"detector_plagio/backend/internal/domain"
+)
+
+// MaxMatrixDocs bounds a matrix request; the pairs grow with its square.
+const MaxMatrixDocs = 500
+
+type MatrixDoc struct {
+	ID               string `json:"id"`
+	Folder           string `json:"folder"`
+	OriginalFilename string `json:"originalFilename"`
+}
+
+// SimilarityMatrix holds the scores of every pair of Docs: Final[i][j]
+// compares Docs[i] with Docs[j]. The matrices are symmetric with 1 on the
+// diagonal.
+type SimilarityMatrix struct {
+	Docs  []MatrixDoc `json:"docs"`
+	Final [][]float64 `json:"final"`
+	Near  [][]float64 `json:"near"`
+	Topic [][]float64 `json:"topic"`
+}
+
+// Matrix compares every pair of the given documents, or of every document in
+// folder with one of roles when ids is empty. Tokens and shingles are
+// computed once per document and the pairs are scored in parallel.
+func (u *Compare) Matrix(ctx context.Context, ids []string, folder string, roles map[string]bool) (SimilarityMatrix, error) {
+	docs, err := u.matrixDocs(ids, folder, roles)
+	if err != nil {
+		return SimilarityMatrix{}, err
+	}
+	n := len(docs)
+	m := SimilarityMatrix{Docs: make([]MatrixDoc, n), Final: square(n), Near: square(n), Topic: square(n)}
+	feats := make([]docFeatures, n)
+	for i, d := range docs {
+		m.Docs[i] = MatrixDoc{ID: d.ID, Folder: d.Folder, OriginalFilename: d.OriginalFilename}
+		feats[i] = u.features(d)
+		m.Final[i][i], m.Near[i][i], m.Topic[i][i] = 1, 1, 1
+	}
+
+	rows := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range rows {
+				for j := i + 1; j < n; j++ {
+					jac, cos, final := u.scores(feats[i], feats[j])
+					// cells [i][j] and [j][i] with j > i belong to row i alone
+					m.Final[i][j], m.Final[j][i] = final, final
+					m.Near[i][j], m.Near[j][i] = jac.Score, jac.Score
+					m.Topic[i][j], m.Topic[j][i] = cos.Score, cos.Score
+				}
+			}
+		}()
+	}
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		rows <- i
+	}
+	close(rows)
+	wg.Wait()
+	return m, ctx.Err()
+}
+
+// matrixDocs takes explicitly listed documents whatever their role.
+func (u *Compare) matrixDocs(ids []string, folder string, roles map[string]bool) ([]domain.Document, error) {
+	var docs []domain.Document
+	switch {
+	case len(ids) > 0:
+		seen := map[string]bool{}
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			d, err := u.repo.Get(id)
+			if err != nil {
+				return nil, fmt.Errorf("document %s: %w", id, err)
+			}
+			docs = append(docs, d)
+		}
+	case folder != "":
+		all, err := u.repo.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range all {
+			if d.Folder == folder && roles[d.CorpusRole()] {
+				docs = append(docs, d)
+			}
+		}
+	default:
+		return nil, errors.New("ids or folder required")
+	}
+	if len(docs) < 2 {
+		return nil, errors.New("at least two documents are needed")
+	}
+	if len(docs) > MaxMatrixDocs {
+		return nil, fmt.Errorf("%d documents exceed the limit of %d", len(docs), MaxMatrixDocs)
+	}
+	return docs, nil
+}
+
+func square(n int) [][]float64 {
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n)
+	}
+	return m
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"math"
+	"os"
+	"testing"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/service"
+)
+
+func TestMatrixMatchesPairwiseScores(t *testing.T) {
+	norm := service.NewNormalizer()
+	texts := []string{
+		"la fotosintesis convierte la energia luminica en energia quimica en los cloroplastos",
+		"la fotosintesis convierte la energia luminica en energia quimica dentro de las hojas",
+		"el ciclo del agua describe la evaporacion la condensacion y la precipitacion",
+	}
+	repo := memRepo{}
+	var ids []string
+	for i, text := range texts {
+		id := string(rune('a' + i))
+		repo[id] = domain.Document{ID: id, Folder: "f", TextContent: norm.Normalize(text)}
+		ids = append(ids, id)
+	}
+	c := NewCompare(repo, norm, service.NewSimilarity())
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	m, err := c.Matrix(context.Background(), ids, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() > 0 {
+		t.Errorf("Matrix logged per pair:\n%s", buf.String())
+	}
+	log.SetOutput(os.Stderr)
+	for i := range ids {
+		for j := range ids {
+			if i == j {
+				continue
+			}
+			res, err := c.CompareTwo(ids[i], ids[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(m.Final[i][j]-res.Final) > 1e-9 || math.Abs(m.Near[i][j]-res.NearDuplicate) > 1e-9 {
+				t.Errorf("cell %d,%d = %f/%f, CompareTwo %f/%f", i, j, m.Final[i][j], m.Near[i][j], res.Final, res.NearDuplicate)
+			}
+		}
+	}
+	if m.Final[0][1] <= m.Final[0][2] {
+		t.Errorf("near copies score %f, unrelated texts %f", m.Final[0][1], m.Final[0][2])
+	}
+}
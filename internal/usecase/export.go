@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+// noteColors are the Word highlight colors given to the sources of an
+// annotated export, in order of contribution.
+var noteColors = []string{"yellow", "cyan", "green", "magenta", "lightGray", "red", "blue", "darkYellow"}
+
+// maxQuoteRunes bounds the source passage quoted in a comment.
+const maxQuoteRunes = 300
+
+// Export produces copies of a submission with its originality report
+// written into the file.
+type Export struct {
+	repo        ports.DocumentRepo
+	originality *Originality
+	annotator   ports.DocxAnnotator
+}
+
+func NewExport(repo ports.DocumentRepo, originality *Originality, annotator ports.DocxAnnotator) *Export {
+	return &Export{repo: repo, originality: originality, annotator: annotator}
+}
+
+// AnnotatedDocx highlights every stretch of the coverage map in the
+// submission id, one color per source, with a comment naming the source and
+// its scores. The stored original is annotated when it is a DOCX; other
+// formats get a DOCX regenerated from the extracted text. It also returns a
+// filename for the download.
+func (u *Export) AnnotatedDocx(id string) ([]byte, string, error) {
+	rep, err := u.originality.Report(id)
+	if err != nil {
+		return nil, "", err
+	}
+	doc, err := u.repo.Get(id)
+	if err != nil {
+		return nil, "", err
+	}
+	var original []byte
+	if strings.EqualFold(doc.Ext, ".docx") {
+		raw, _ := u.repo.PathFor(id)
+		if original, err = os.ReadFile(raw); err != nil {
+			return nil, "", err
+		}
+	}
+
+	sources := map[string]int{}
+	for i, s := range rep.Sources {
+		sources[s.ID] = i
+	}
+	notes := make([]domain.TextNote, 0, len(rep.Coverage))
+	for _, span := range rep.Coverage {
+		i := sources[span.SourceID]
+		src := rep.Sources[i]
+		comment := fmt.Sprintf("Source: %s (%s, %s)\nContributes %.1f%% of this document; covers %.1f%% in total.",
+			src.OriginalFilename, src.ID, src.Role, src.ContributionPercent, src.CoveragePercent)
+		for _, p := range src.Passages {
+			if p.DocStart == span.SourceStart && p.DocEnd == span.SourceEnd {
+				comment += "\nIn the source: “" + quote(p.Text, maxQuoteRunes) + "”"
+				break
+			}
+		}
+		notes = append(notes, domain.TextNote{
+			Start: span.Start, End: span.End,
+			Color:   noteColors[i%len(noteColors)],
+			Author:  "DocSim",
+			Comment: comment,
+		})
+	}
+	out, err := u.annotator.Annotate(original, doc.TextContent, notes)
+	if err != nil {
+		return nil, "", err
+	}
+	name := strings.TrimSuffix(doc.OriginalFilename, doc.Ext)
+	if name == "" {
+		name = doc.ID
+	}
+	return out, name + "-annotated.docx", nil
+}
+
+// quote shortens s to at most n runes, cutting at a word boundary.
+func quote(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	cut := string(r[:n])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + " …"
+}
@@ -1,40 +1,168 @@
 package usecase
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"detector_plagio/backend/internal/config"
 	"detector_plagio/backend/internal/domain"
 	"detector_plagio/backend/internal/ports"
 )
 
+// TypeMismatchError is returned when the upload content does not match the
+// extension it was declared with, or when its type cannot be recognized at all.
+type TypeMismatchError struct {
+	Declared string
+	Detected ports.DetectedType
+}
+
+func (e *TypeMismatchError) Error() string {
+	if e.Declared == "" {
+		return "unsupported content type " + e.Detected.MIME
+	}
+	return fmt.Sprintf("declared %s but content is %s", e.Declared, e.Detected.MIME)
+}
+
 type Ingest struct {
-	cfg        *config.Config
-	repo       ports.DocumentRepo
-	extractors []ports.Extractor
-	norm       ports.Normalizer
+	cfg         *config.Config
+	repo        ports.DocumentRepo
+	extractors  ports.ExtractorRegistry
+	norm        ports.Normalizer
+	detector    ports.TypeDetector
+	decoder     ports.TextDecoder
+	obfuscation ports.ObfuscationAnalyzer
+	ocr         ports.OCR
+	quarantine  ports.QuarantineRepo
+	index       ports.TextIndex
+	stats       *extractionCounters
 }
 
-func NewIngest(cfg *config.Config, repo ports.DocumentRepo, ex []ports.Extractor, n ports.Normalizer) *Ingest {
-	return &Ingest{cfg: cfg, repo: repo, extractors: ex, norm: n}
+func NewIngest(cfg *config.Config, repo ports.DocumentRepo, ex ports.ExtractorRegistry, n ports.Normalizer, d ports.TypeDetector, dec ports.TextDecoder, ob ports.ObfuscationAnalyzer, ocr ports.OCR, q ports.QuarantineRepo, idx ports.TextIndex) *Ingest {
+	return &Ingest{cfg: cfg, repo: repo, extractors: ex, norm: n, detector: d, decoder: dec, obfuscation: ob, ocr: ocr, quarantine: q, index: idx, stats: newExtractionCounters()}
 }
 
-func (u *Ingest) SaveAndIndex(id, folder, originalFilename string, data []byte) (domain.Document, error) {
-	ext := strings.ToLower(filepath.Ext(originalFilename))
-	doc := domain.Document{ID: id, Folder: folder, Filename: id + ext, OriginalFilename: originalFilename, Size: int64(len(data)), Ext: ext}
-	var ex ports.Extractor
-	for _, e := range u.extractors { if e.CanHandle(ext) { ex = e; break } }
-	if ex == nil { return doc, errors.New("no extractor for " + ext) }
-	// Extract text directly from the provided data (file content)
-	text, err := ex.ExtractFromBytes(data, ext); if err != nil { return doc, err }
-	doc.TextContent = u.norm.Normalize(text)
+// ExtractionError is returned by Process when the extractor itself failed,
+// as opposed to the upload being rejected before extraction.
+type ExtractionError struct {
+	Extractor string
+	Err       error
+}
+
+func (e *ExtractionError) Error() string { return e.Err.Error() }
+func (e *ExtractionError) Unwrap() error { return e.Err }
+
+// SaveAndIndex extracts, normalizes and stores an upload. The caller fills in
+// the identity of doc (ID, Folder, OriginalFilename, Owner, OwnerVerified, Role); the rest is derived.
+func (u *Ingest) SaveAndIndex(ctx context.Context, doc domain.Document, data []byte) (domain.Document, error) {
+	doc, err := u.Process(ctx, doc, data)
+	var failed *ExtractionError
+	if errors.As(err, &failed) { return doc, u.quarantineFailed(doc, failed.Extractor, data, failed.Err) }
+	if err != nil { return doc, err }
 	// Save the document *after* TextContent is populated
 	if err := u.repo.Save(doc, data); err != nil { return doc, err }
-	_, txtPath := u.repo.PathFor(id)
+	_, txtPath := u.repo.PathFor(doc.ID)
 	// Write the extracted text to a file
 	if err := os.WriteFile(txtPath, []byte(doc.TextContent), 0644); err != nil { return doc, err }
+	// The stored text is authoritative; Search.Sync repairs a missed index update
+	if err := u.index.Put(doc); err != nil { log.Printf("Ingest: indexing %s: %v", doc.ID, err) }
+	return doc, nil
+}
+
+// Process runs detection, extraction and normalization on data and returns
+// the document that SaveAndIndex would store, without storing anything.
+func (u *Ingest) Process(ctx context.Context, doc domain.Document, data []byte) (domain.Document, error) {
+	if !domain.ValidRole(doc.Role) { return doc, fmt.Errorf("unknown role %q", doc.Role) }
+	declared := strings.ToLower(filepath.Ext(doc.OriginalFilename))
+	detected := u.detector.Detect(data)
+	ext := declared
+	if ext == "" { ext = detected.Ext }
+	doc.Filename, doc.Size, doc.Ext, doc.MimeType, doc.SHA256 = doc.ID+ext, int64(len(data)), ext, detected.MIME, checksum(data)
+	if !u.detector.Compatible(ext, detected) {
+		return doc, &TypeMismatchError{Declared: declared, Detected: detected}
+	}
+	ex := u.extractors.For(ext)
+	if ex == nil { return doc, errors.New("no extractor for " + ext) }
+	// Text uploads are transcoded to UTF-8 first; the raw file is stored as received
+	input := data
+	if strings.HasPrefix(detected.MIME, "text/") { input, doc.Encoding = u.decoder.Decode(data) }
+	// Extract text directly from the provided data (file content)
+	text, err := ex.ExtractFromBytes(ctx, input, ext)
+	// A client that went away is not the file's fault: neither counted nor quarantined
+	if errors.Is(err, context.Canceled) { return doc, err }
+	u.stats.record(extractorName(ex), err != nil)
+	if err != nil { return doc, &ExtractionError{Extractor: extractorName(ex), Err: err} }
+	// Document properties are a bonus; a format without them must still ingest
+	if mx, ok := ex.(ports.MetadataExtractor); ok {
+		meta, err := mx.ExtractMetadata(ctx, input, ext)
+		if err != nil { log.Printf("Ingest: metadata for %s: %v", doc.ID, err) }
+		if len(meta) > 0 { doc.Metadata = meta }
+	}
+	// Scanned pages come back nearly empty; OCR them or at least say so
+	text, lowText := u.checkTextDensity(ctx, &doc, text, input, ext)
+	// Undo homoglyph and zero-width tricks before the normalizer drops those characters
+	text, integrity := u.obfuscation.Analyze(text, input, ext)
+	if lowText { integrity.Flags = append(integrity.Flags, domain.FlagLowText) }
+	doc.Integrity = &integrity
+	if len(integrity.Flags) > 0 { log.Printf("Ingest: %s integrity flags %v", doc.ID, integrity.Flags) }
+	doc.TextContent = u.norm.Normalize(text)
+	doc.Pipeline = u.pipeline(ex)
 	return doc, nil
 }
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// BatchItem is one file of a multi-file upload; Load is called only once a
+// worker slot is free so that pending files are not all held in memory.
+type BatchItem struct {
+	Doc  domain.Document
+	Load func() ([]byte, error)
+}
+
+type BatchResult struct {
+	OriginalFilename string           `json:"originalFilename"`
+	ID               string           `json:"id"`
+	Document         *domain.Document `json:"document,omitempty"`
+	Error            string           `json:"error,omitempty"`
+}
+
+// SaveAndIndexBatch ingests items with at most parallel concurrent
+// extractions. A failing item is reported in its result and does not stop
+// the others; results keep the order of items.
+func (u *Ingest) SaveAndIndexBatch(ctx context.Context, items []BatchItem, parallel int) []BatchResult {
+	if parallel < 1 { parallel = 1 }
+	results := make([]BatchResult, len(items))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, it := range items {
+		results[i] = BatchResult{OriginalFilename: it.Doc.OriginalFilename, ID: it.Doc.ID}
+		wg.Add(1)
+		go func(i int, it BatchItem) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i].Error = ctx.Err().Error()
+				return
+			}
+			defer func() { <-sem }()
+			data, err := it.Load()
+			if err != nil { results[i].Error = err.Error(); return }
+			doc, err := u.SaveAndIndex(ctx, it.Doc, data)
+			if err != nil { results[i].Error = err.Error(); return }
+			results[i].Document = &doc
+		}(i, it)
+	}
+	wg.Wait()
+	return results
+}
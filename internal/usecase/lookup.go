@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+// LookupPassage is one run of consecutive shingles shared by the excerpt and
+// a document. Offsets are in runes: Query* into the normalized excerpt
+// returned with the result, Doc* into the document's textContent.
+type LookupPassage struct {
+	QueryStart int    `json:"queryStart"`
+	QueryEnd   int    `json:"queryEnd"`
+	DocStart   int    `json:"docStart"`
+	DocEnd     int    `json:"docEnd"`
+	Text       string `json:"text"`
+	// from and to delimit the passage in query tokens, to exclusive.
+	from, to int
+}
+
+type LookupHit struct {
+	ID               string          `json:"id"`
+	Folder           string          `json:"folder"`
+	OriginalFilename string          `json:"originalFilename"`
+	CoveragePercent  float64         `json:"coveragePercent"`
+	MatchedTokens    int             `json:"matchedTokens"`
+	Passages         []LookupPassage `json:"passages"`
+}
+
+type LookupResult struct {
+	NormalizedText string      `json:"normalizedText"`
+	Tokens         int         `json:"tokens"`
+	Hits           []LookupHit `json:"hits"`
+}
+
+// tokenSpan is a Normalizer token with its rune offsets in the normalized text.
+type tokenSpan struct {
+	term       string
+	start, end int
+}
+
+// Lookup finds the stored documents that contain passages of a pasted
+// excerpt. The excerpt goes through the same Normalizer and shingling as
+// Compare and is never stored.
+type Lookup struct {
+	repo  ports.DocumentRepo
+	norm  ports.Normalizer
+	index ports.TextIndex
+}
+
+func NewLookup(repo ports.DocumentRepo, n ports.Normalizer, index ports.TextIndex) *Lookup {
+	return &Lookup{repo: repo, norm: n, index: index}
+}
+
+// Find returns every document sharing at least one shingle with text,
+// ordered by the share of the excerpt it covers. Excluded documents are
+// skipped and, with folders set, so are documents in other folders.
+func (u *Lookup) Find(text string, folders []string) (LookupResult, error) {
+	normalized := u.norm.Normalize(text)
+	allowed := map[string]bool{}
+	for _, f := range folders {
+		allowed[f] = true
+	}
+	query, hits, err := u.find(normalized, func(doc domain.Document) bool {
+		return doc.CorpusRole() != domain.RoleExcluded && (len(allowed) == 0 || allowed[doc.Folder])
+	})
+	if err != nil {
+		return LookupResult{NormalizedText: normalized, Tokens: len(query)}, fmt.Errorf("excerpt %w", err)
+	}
+	return LookupResult{NormalizedText: normalized, Tokens: len(query), Hits: hits}, nil
+}
+
+// find matches normalized text against the stored documents accepted by
+// keep and returns its tokens and the hits, best covering first.
+func (u *Lookup) find(normalized string, keep func(domain.Document) bool) ([]tokenSpan, []LookupHit, error) {
+	query := u.spans(normalized)
+	if len(query) < shingleSize {
+		return query, nil, fmt.Errorf("too short: need at least %d significant words", shingleSize)
+	}
+	shingles := u.norm.Shingles(terms(query), shingleSize)
+	at := map[string][]int{}
+	for i, sh := range shingles {
+		at[sh] = append(at[sh], i)
+	}
+
+	hits := []LookupHit{}
+	for _, id := range u.candidates(shingles) {
+		doc, err := u.repo.Get(id)
+		if err != nil || !keep(doc) {
+			continue
+		}
+		if hit, ok := u.align(doc, query, at); ok {
+			hits = append(hits, hit)
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].MatchedTokens != hits[j].MatchedTokens {
+			return hits[i].MatchedTokens > hits[j].MatchedTokens
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return query, hits, nil
+}
+
+// Passages aligns two documents directly: Query* offsets of the result are
+// in a's textContent and Doc* offsets in b's.
+func (u *Lookup) Passages(a, b domain.Document) []LookupPassage {
+	query := u.spans(a.TextContent)
+	if len(query) < shingleSize {
+		return nil
+	}
+	at := map[string][]int{}
+	for i, sh := range u.norm.Shingles(terms(query), shingleSize) {
+		at[sh] = append(at[sh], i)
+	}
+	hit, _ := u.align(b, query, at)
+	return hit.Passages
+}
+
+// candidates uses the inverted index to narrow the search to documents that
+// contain every word of at least one shingle.
+func (u *Lookup) candidates(shingles []string) []string {
+	docs := map[string]map[string]bool{}
+	contains := func(word string) map[string]bool {
+		if set, ok := docs[word]; ok {
+			return set
+		}
+		set := map[string]bool{}
+		for _, t := range u.index.Tokens(word) {
+			for id := range u.index.Postings(t.Term) {
+				set[id] = true
+			}
+		}
+		docs[word] = set
+		return set
+	}
+	found := map[string]bool{}
+	for _, sh := range shingles {
+		words := strings.Fields(sh)
+		for id := range contains(words[0]) {
+			if found[id] {
+				continue
+			}
+			all := true
+			for _, w := range words[1:] {
+				if !contains(w)[id] {
+					all = false
+					break
+				}
+			}
+			found[id] = all
+		}
+	}
+	ids := make([]string, 0, len(found))
+	for id, ok := range found {
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// align walks the document's shingles and chains the ones shared with the
+// excerpt along the same diagonal into passages.
+func (u *Lookup) align(doc domain.Document, query []tokenSpan, at map[string][]int) (LookupHit, bool) {
+	dt := u.spans(doc.TextContent)
+	if len(dt) < shingleSize {
+		return LookupHit{}, false
+	}
+	type run struct{ q0, d0, q1, d1 int } // first and last matching shingle
+	var runs []*run
+	open := map[int]*run{} // by diagonal d-q, the run that ended at the previous shingle
+	for i, sh := range u.norm.Shingles(terms(dt), shingleSize) {
+		for _, q := range at[sh] {
+			if r := open[i-q]; r != nil && r.d1 == i-1 {
+				r.q1, r.d1 = q, i
+				continue
+			}
+			r := &run{q0: q, d0: i, q1: q, d1: i}
+			open[i-q] = r
+			runs = append(runs, r)
+		}
+	}
+	if len(runs) == 0 {
+		return LookupHit{}, false
+	}
+
+	covered := make([]bool, len(query)) // passages may overlap in the query
+	runes := []rune(doc.TextContent)
+	hit := LookupHit{ID: doc.ID, Folder: doc.Folder, OriginalFilename: doc.OriginalFilename}
+	for _, r := range runs {
+		qEnd, dEnd := r.q1+shingleSize-1, r.d1+shingleSize-1
+		for k := r.q0; k <= qEnd; k++ {
+			covered[k] = true
+		}
+		hit.Passages = append(hit.Passages, LookupPassage{
+			QueryStart: query[r.q0].start, QueryEnd: query[qEnd].end,
+			DocStart: dt[r.d0].start, DocEnd: dt[dEnd].end,
+			Text: string(runes[dt[r.d0].start:dt[dEnd].end]),
+			from: r.q0, to: qEnd + 1,
+		})
+	}
+	for _, c := range covered {
+		if c {
+			hit.MatchedTokens++
+		}
+	}
+	hit.CoveragePercent = percent(hit.MatchedTokens, len(query))
+	sort.Slice(hit.Passages, func(i, j int) bool { return hit.Passages[i].DocStart < hit.Passages[j].DocStart })
+	return hit, true
+}
+
+// spans locates the tokens of Tokenize in normalized text. Tokenize keeps a
+// subsequence of the words of the normalized text (it only drops stop
+// words), so matching them in order recovers their offsets.
+func (u *Lookup) spans(normalized string) []tokenSpan {
+	toks := u.norm.Tokenize(normalized)
+	out := make([]tokenSpan, 0, len(toks))
+	pos := 0
+	for _, w := range strings.Split(normalized, " ") {
+		n := utf8.RuneCountInString(w)
+		if len(out) < len(toks) && w == toks[len(out)] {
+			out = append(out, tokenSpan{term: w, start: pos, end: pos + n})
+		}
+		pos += n + 1
+	}
+	return out
+}
+
+func terms(spans []tokenSpan) []string {
+	out := make([]string, len(spans))
+	for i, s := range spans {
+		out[i] = s.term
+	}
+	return out
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/service"
+)
+
+// runeIndex is the rune offset of sub in s.
+func runeIndex(t *testing.T, s, sub string) int {
+	t.Helper()
+	i := strings.Index(s, sub)
+	if i < 0 {
+		t.Fatalf("%q not in %q", sub, s)
+	}
+	return utf8.RuneCountInString(s[:i])
+}
+
+func TestLookupSpans(t *testing.T) {
+	u := NewLookup(memRepo{}, service.NewNormalizer(), nil)
+	cases := []struct {
+		text string
+		want []tokenSpan
+	}{
+		{"el gato de la casa come pescado", []tokenSpan{{"gato", 3, 7}, {"casa", 14, 18}, {"come", 19, 23}, {"pescado", 24, 31}}},
+		{"canción del árbol", []tokenSpan{{"canción", 0, 7}, {"del", 8, 11}, {"árbol", 12, 17}}},
+		{"la de el", []tokenSpan{}},
+		{"", []tokenSpan{}},
+	}
+	for _, c := range cases {
+		if got := u.spans(c.text); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("spans(%q) = %v, want %v", c.text, got, c.want)
+		}
+	}
+}
+
+const (
+	passageA = "la fotosíntesis convierte la energía lumínica en energía química almacenada en glucosa"
+	passageB = "los cloroplastos liberan oxígeno como subproducto esencial para toda la vida terrestre"
+)
+
+func TestLookupFind(t *testing.T) {
+	repo, norm, index := corpus(t, map[string]string{
+		"src":   "Texto previo muy distinto. " + passageA + ". Otro párrafo sin relación alguna; " + passageB + ".",
+		"other": "Un documento que no comparte ningún pasaje con la consulta enviada hoy.",
+		"half":  "Comienzo diferente y luego " + passageB + " y un final.",
+	})
+	excluded := repo["half"]
+	excluded.Role = domain.RoleExcluded
+	repo["half"] = excluded
+	u := NewLookup(repo, norm, index)
+
+	query := "Mi introducción propia. " + strings.ToUpper(passageA[:1]) + passageA[1:] + ", y después " + passageB + "!"
+	res, err := u.Find(query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Hits) != 1 || res.Hits[0].ID != "src" {
+		t.Fatalf("Hits = %+v, want only src", res.Hits)
+	}
+	hit := res.Hits[0]
+	if len(hit.Passages) != 2 {
+		t.Fatalf("Passages = %+v, want 2", hit.Passages)
+	}
+	docText := repo["src"].TextContent
+	// passages start and end on significant words, so leading stop words are left out
+	for i, want := range []string{strings.TrimPrefix(passageA, "la "), strings.TrimPrefix(passageB, "los ")} {
+		p := hit.Passages[i]
+		if p.Text != want {
+			t.Errorf("passage %d Text = %q, want %q", i, p.Text, want)
+		}
+		if got := string([]rune(docText)[p.DocStart:p.DocEnd]); got != want {
+			t.Errorf("passage %d doc[%d:%d] = %q", i, p.DocStart, p.DocEnd, got)
+		}
+		if got := string([]rune(res.NormalizedText)[p.QueryStart:p.QueryEnd]); got != want {
+			t.Errorf("passage %d query[%d:%d] = %q", i, p.QueryStart, p.QueryEnd, got)
+		}
+		if p.DocStart != runeIndex(t, docText, want) || p.QueryStart != runeIndex(t, res.NormalizedText, want) {
+			t.Errorf("passage %d starts at doc %d, query %d", i, p.DocStart, p.QueryStart)
+		}
+	}
+	matched := len(norm.Tokenize(passageA)) + len(norm.Tokenize(passageB))
+	if hit.MatchedTokens != matched || res.Tokens != len(norm.Tokenize(query)) {
+		t.Errorf("MatchedTokens = %d of %d, want %d of %d", hit.MatchedTokens, res.Tokens, matched, len(norm.Tokenize(query)))
+	}
+
+	if res, err := u.Find(query, []string{"elsewhere"}); err != nil || len(res.Hits) != 0 {
+		t.Errorf("Find in another folder = %+v, %v", res.Hits, err)
+	}
+	if _, err := u.Find("muy pocas palabras", nil); err == nil {
+		t.Error("an excerpt shorter than a shingle should fail")
+	}
+}
+
+func TestLookupPassagesBetweenDocuments(t *testing.T) {
+	norm := service.NewNormalizer()
+	u := NewLookup(memRepo{}, norm, nil)
+	a := domain.Document{ID: "a", TextContent: norm.Normalize("Empieza así: " + passageB + ". Termina aquí.")}
+	b := domain.Document{ID: "b", TextContent: norm.Normalize("Otro comienzo bastante más largo que el anterior; " + passageB)}
+	ps := u.Passages(a, b)
+	if len(ps) != 1 {
+		t.Fatalf("Passages = %+v, want 1", ps)
+	}
+	p := ps[0]
+	ra, rb := []rune(a.TextContent), []rune(b.TextContent)
+	if string(ra[p.QueryStart:p.QueryEnd]) != p.Text || string(rb[p.DocStart:p.DocEnd]) != p.Text {
+		t.Errorf("offsets do not delimit %q: a[%d:%d], b[%d:%d]", p.Text, p.QueryStart, p.QueryEnd, p.DocStart, p.DocEnd)
+	}
+	if !strings.HasPrefix(p.Text, "cloroplastos") || !strings.HasSuffix(p.Text, "terrestre") {
+		t.Errorf("Text = %q", p.Text)
+	}
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"detector_plagio/backend/internal/domain"
+)
+
+// checkTextDensity measures the extracted text per page and, for PDFs, sends
+// the pages below the threshold to the OCR stage when one is configured.
+// pdftotext separates pages with form feeds; the page count from the
+// document properties covers scans whose trailing pages came back empty.
+// It returns the text with recognized pages substituted and whether the
+// document is still low on text.
+func (u *Ingest) checkTextDensity(ctx context.Context, doc *domain.Document, text string, data []byte, ext string) (string, bool) {
+	threshold := u.cfg.LowTextCharsPerPage
+	metaPages, _ := strconv.Atoi(doc.Metadata[domain.MetaPages])
+	stats := &domain.TextStats{}
+	doc.TextStats = stats
+
+	if ext != ".pdf" {
+		// other formats have no page breaks in the extracted text, and a
+		// short plain-text upload is just short: nothing was lost extracting it
+		stats.Pages = max(1, metaPages)
+		stats.Chars = countChars(text)
+		stats.CharsPerPage = stats.Chars / stats.Pages
+		return text, stats.CharsPerPage < threshold && !strings.HasPrefix(doc.MimeType, "text/")
+	}
+
+	pages := strings.Split(text, "\f")
+	if metaPages > len(pages) {
+		pages = append(pages, make([]string, metaPages-len(pages))...)
+	}
+	counts := make([]int, len(pages))
+	var low []int
+	for i, p := range pages {
+		counts[i] = countChars(p)
+		if counts[i] < threshold {
+			low = append(low, i+1)
+		}
+	}
+
+	if u.ocr != nil && len(low) > 0 {
+		todo := low[:min(len(low), u.cfg.OCRMaxPages)]
+		recognized, err := u.ocr.Recognize(ctx, data, ext, todo)
+		if err != nil {
+			log.Printf("Ingest: OCR for %s: %v", doc.ID, err)
+		}
+		for _, p := range todo {
+			t, ok := recognized[p]
+			if n := countChars(t); ok && n > counts[p-1] {
+				pages[p-1], counts[p-1] = t, n
+				stats.OCRPages = append(stats.OCRPages, p)
+			}
+		}
+		if len(stats.OCRPages) > 0 {
+			text = strings.Join(pages, "\f")
+			low = low[:0]
+			for i, n := range counts {
+				if n < threshold {
+					low = append(low, i+1)
+				}
+			}
+		}
+	}
+
+	stats.Pages = len(pages)
+	for _, n := range counts {
+		stats.Chars += n
+	}
+	stats.CharsPerPage = stats.Chars / stats.Pages
+	stats.LowTextPages = low
+	// a blank or figure-only page is normal; half of the document is not
+	return text, stats.CharsPerPage < threshold || len(low)*2 > stats.Pages
+}
+
+// countChars counts letters and digits, so layout whitespace does not count as text.
+func countChars(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"detector_plagio/backend/internal/config"
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+	"detector_plagio/backend/internal/service"
+)
+
+// pdfPage is page text long enough to clear the test threshold.
+func pdfPage(n int) string {
+	return "pagina " + strconv.Itoa(n) + " con texto extraido suficiente para el umbral"
+}
+
+// ocrPage is what the fake OCR command prints for page n.
+func ocrPage(n int) string {
+	return "pagina " + strconv.Itoa(n) + " reconocida por el ocr a partir del escaneo"
+}
+
+// lowTextIngest returns an Ingest whose pdftotext prints pages (joined with
+// form feeds, as the real tool does), whose pdfinfo reports pageCount and,
+// unless ocrFails is nil, whose OCR command prints ocrPage for every page
+// except those in ocrFails.
+func lowTextIngest(t *testing.T, pages []string, pageCount int, ocrFails []int) *Ingest {
+	t.Helper()
+	dir := t.TempDir()
+	script := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	out := strings.Join(pages, "\f") + "\f"
+	if err := os.WriteFile(filepath.Join(dir, "out.txt"), []byte(out), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script("pdftotext", "cat >/dev/null\ncat "+filepath.Join(dir, "out.txt")+"\n")
+	script("pdfinfo", "echo 'Pages: "+strconv.Itoa(pageCount)+"'\n")
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cfg := &config.Config{LowTextCharsPerPage: 20, OCRMaxPages: 10}
+	var ocr ports.OCR
+	if ocrFails != nil {
+		var fail strings.Builder
+		for _, p := range ocrFails {
+			fail.WriteString(strconv.Itoa(p) + ") exit 1;; ")
+		}
+		ocrScript := script("ocr", `case "$1" in `+fail.String()+`esac
+echo "pagina $1 reconocida por el ocr a partir del escaneo"
+`)
+		ocr = service.NewCommandOCR(ocrScript+" {page} {input}", time.Minute)
+	}
+	registry := service.NewExtractorRegistry(service.NewPDFToTextExtractor(time.Minute))
+	return NewIngest(cfg, nil, registry, service.NewNormalizer(), service.NewContentSniffer(), service.NewCharsetDecoder(), service.NewObfuscationDetector(), ocr, nil, nil)
+}
+
+func TestCheckTextDensity(t *testing.T) {
+	cases := []struct {
+		name      string
+		pages     []string
+		pageCount int
+		ocrFails  []int // nil: no OCR configured
+		wantPages []string
+		ocrPages  []int
+		lowPages  []int
+		lowText   bool
+	}{
+		{
+			name:      "text on every page",
+			pages:     []string{pdfPage(1), pdfPage(2)},
+			pageCount: 2,
+			wantPages: []string{pdfPage(1), pdfPage(2)},
+		},
+		{
+			name:      "scanned first page without OCR",
+			pages:     []string{"", pdfPage(2), pdfPage(3)},
+			pageCount: 3,
+			wantPages: []string{pdfPage(2), pdfPage(3)},
+			lowPages:  []int{1},
+		},
+		{
+			name:      "scanned first page is recognized in place",
+			pages:     []string{"", pdfPage(2), pdfPage(3)},
+			pageCount: 3,
+			ocrFails:  []int{},
+			wantPages: []string{ocrPage(1), pdfPage(2), pdfPage(3)},
+			ocrPages:  []int{1},
+		},
+		{
+			name:      "missing trailing pages are recognized",
+			pages:     []string{pdfPage(1)},
+			pageCount: 3,
+			ocrFails:  []int{},
+			wantPages: []string{pdfPage(1), ocrPage(2), ocrPage(3)},
+			ocrPages:  []int{2, 3},
+		},
+		{
+			name:      "failed OCR page stays low",
+			pages:     []string{"", "", pdfPage(3)},
+			pageCount: 3,
+			ocrFails:  []int{2},
+			wantPages: []string{ocrPage(1), pdfPage(3)},
+			ocrPages:  []int{1},
+			lowPages:  []int{2},
+		},
+		{
+			name:      "mostly scanned without OCR",
+			pages:     []string{"", "", pdfPage(3)},
+			pageCount: 3,
+			wantPages: []string{pdfPage(3)},
+			lowPages:  []int{1, 2},
+			lowText:   true,
+		},
+	}
+	norm := service.NewNormalizer()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			u := lowTextIngest(t, c.pages, c.pageCount, c.ocrFails)
+			doc, err := u.Process(context.Background(), domain.Document{ID: "d", OriginalFilename: "scan.pdf"}, []byte("%PDF-1.4\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := norm.Normalize(strings.Join(c.wantPages, " ")); doc.TextContent != want {
+				t.Errorf("TextContent = %q, want %q", doc.TextContent, want)
+			}
+			stats := doc.TextStats
+			if stats == nil {
+				t.Fatal("TextStats not set")
+			}
+			if stats.Pages != c.pageCount {
+				t.Errorf("Pages = %d, want %d", stats.Pages, c.pageCount)
+			}
+			if !slices.Equal(stats.OCRPages, c.ocrPages) {
+				t.Errorf("OCRPages = %v, want %v", stats.OCRPages, c.ocrPages)
+			}
+			if !slices.Equal(stats.LowTextPages, c.lowPages) {
+				t.Errorf("LowTextPages = %v, want %v", stats.LowTextPages, c.lowPages)
+			}
+			if got := slices.Contains(doc.Integrity.Flags, domain.FlagLowText); got != c.lowText {
+				t.Errorf("low-text flag = %v, want %v (flags %v)", got, c.lowText, doc.Integrity.Flags)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"sort"
+	"strings"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+// DefaultCollisionFields are the properties that tie a file to the person or
+// original file it came from.
+var DefaultCollisionFields = []string{domain.MetaAuthor, domain.MetaLastModifiedBy, domain.MetaDocID, domain.MetaRsidRoot, domain.MetaTemplate}
+
+// stockTemplates are shared by every default install and prove nothing.
+var stockTemplates = map[string]bool{"normal": true, "normal.dot": true, "normal.dotm": true, "normal.dotx": true}
+
+type MetadataCollision struct {
+	Field     string   `json:"field"`
+	Value     string   `json:"value"`
+	Owners    []string `json:"owners"`
+	Documents []string `json:"documents"`
+	// UnownedDocuments share the value but have no owner, so they neither
+	// make nor break a collision.
+	UnownedDocuments []string `json:"unownedDocuments,omitempty"`
+	// UnverifiedOwners were typed in by an anonymous uploader rather than
+	// taken from a login, so they may not be who they say they are.
+	UnverifiedOwners []string `json:"unverifiedOwners,omitempty"`
+}
+
+type Metadata struct {
+	repo ports.DocumentRepo
+}
+
+func NewMetadata(repo ports.DocumentRepo) *Metadata {
+	return &Metadata{repo: repo}
+}
+
+// Collisions groups documents that share a value in any of fields and keeps
+// the groups spanning more than one owner. Documents without an owner are
+// listed apart: in a legacy corpus nobody has one, and counting each as its
+// own owner would flag every shared template. An empty folder means the
+// whole corpus.
+func (u *Metadata) Collisions(fields []string, folder string) ([]MetadataCollision, error) {
+	docs, err := u.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		fields = DefaultCollisionFields
+	}
+	type group struct {
+		c          *MetadataCollision
+		owners     map[string]bool
+		unverified map[string]bool
+	}
+	groups := map[string]*group{}
+	var order []string
+	for _, d := range docs {
+		if folder != "" && d.Folder != folder {
+			continue
+		}
+		for _, f := range fields {
+			v := strings.TrimSpace(d.Metadata[f])
+			norm := strings.ToLower(v)
+			if v == "" || (f == domain.MetaTemplate && stockTemplates[norm]) {
+				continue
+			}
+			key := f + "\x00" + norm
+			g, ok := groups[key]
+			if !ok {
+				g = &group{c: &MetadataCollision{Field: f, Value: v}, owners: map[string]bool{}, unverified: map[string]bool{}}
+				groups[key] = g
+				order = append(order, key)
+			}
+			if d.Owner == "" {
+				g.c.UnownedDocuments = append(g.c.UnownedDocuments, d.ID)
+				continue
+			}
+			g.c.Documents = append(g.c.Documents, d.ID)
+			if !g.owners[d.Owner] {
+				g.owners[d.Owner] = true
+				g.c.Owners = append(g.c.Owners, d.Owner)
+			}
+			if !d.OwnerVerified && !g.unverified[d.Owner] {
+				g.unverified[d.Owner] = true
+				g.c.UnverifiedOwners = append(g.c.UnverifiedOwners, d.Owner)
+			}
+		}
+	}
+	out := []MetadataCollision{}
+	for _, k := range order {
+		if g := groups[k]; len(g.owners) > 1 {
+			out = append(out, *g.c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return len(out[i].Owners) > len(out[j].Owners) })
+	return out, nil
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+// ErrNotSubmission is returned when a reference or excluded document is
+// checked as a suspect: those are sources, never suspects.
+var ErrNotSubmission = errors.New("only submissions are checked against the corpus")
+
+// OriginalitySource is a document the submission shares passages with.
+// CoveragePercent counts every token it matches; ContributionPercent only
+// the tokens attributed to it in the coverage map.
+type OriginalitySource struct {
+	Role                string  `json:"role"`
+	ContributionPercent float64 `json:"contributionPercent"`
+	ContributedTokens   int     `json:"contributedTokens"`
+	LookupHit
+}
+
+// CoverageSpan is a copied stretch of the submission attributed to a single
+// source. Start and End are rune offsets in the submission's textContent;
+// SourceStart and SourceEnd delimit, in the source's textContent, the
+// passage the stretch belongs to.
+type CoverageSpan struct {
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	SourceID    string `json:"sourceId"`
+	Role        string `json:"role"`
+	SourceStart int    `json:"sourceStart"`
+	SourceEnd   int    `json:"sourceEnd"`
+}
+
+// OriginalityReport merges the passages of every source into one coverage
+// map over the submission. Each copied token is attributed to its best
+// source, so CopiedPercent (= ReferencePercent + PeerPercent) counts it
+// once. Percentages are of the submission's tokens.
+type OriginalityReport struct {
+	ID                 string              `json:"id"`
+	Folder             string              `json:"folder"`
+	OriginalFilename   string              `json:"originalFilename"`
+	Tokens             int                 `json:"tokens"`
+	OriginalityPercent float64             `json:"originalityPercent"`
+	CopiedPercent      float64             `json:"copiedPercent"`
+	ReferencePercent   float64             `json:"referencePercent"`
+	PeerPercent        float64             `json:"peerPercent"`
+	Coverage           []CoverageSpan      `json:"coverage"`
+	Sources            []OriginalitySource `json:"sources"`
+}
+
+type Originality struct {
+	repo   ports.DocumentRepo
+	lookup *Lookup
+}
+
+func NewOriginality(repo ports.DocumentRepo, lookup *Lookup) *Originality {
+	return &Originality{repo: repo, lookup: lookup}
+}
+
+// Report lists the reference and peer documents the submission id draws
+// passages from, using the same alignment as Lookup, with the coverage map.
+func (u *Originality) Report(id string) (OriginalityReport, error) {
+	doc, err := u.repo.Get(id)
+	if err != nil {
+		return OriginalityReport{}, err
+	}
+	if doc.CorpusRole() != domain.RoleSubmission {
+		return OriginalityReport{}, ErrNotSubmission
+	}
+	rep := OriginalityReport{ID: doc.ID, Folder: doc.Folder, OriginalFilename: doc.OriginalFilename, OriginalityPercent: 100, Coverage: []CoverageSpan{}, Sources: []OriginalitySource{}}
+	roles := map[string]string{}
+	query, hits, err := u.lookup.find(doc.TextContent, func(other domain.Document) bool {
+		roles[other.ID] = other.CorpusRole()
+		return other.ID != doc.ID && other.CorpusRole() != domain.RoleExcluded
+	})
+	rep.Tokens = len(query)
+	if err != nil {
+		return rep, nil // too short to share a passage with anything
+	}
+
+	for _, h := range hits {
+		rep.Sources = append(rep.Sources, OriginalitySource{Role: roles[h.ID], LookupHit: h})
+	}
+	best := attribute(len(query), rep.Sources)
+
+	var ref, peer int
+	for k := 0; k < len(best); {
+		if best[k] == nil {
+			k++
+			continue
+		}
+		a := best[k]
+		end := k
+		for end < len(best) && best[end] != nil && *best[end] == *a {
+			end++
+		}
+		src := &rep.Sources[a.source]
+		src.ContributedTokens += end - k
+		if src.Role == domain.RoleReference {
+			ref += end - k
+		} else {
+			peer += end - k
+		}
+		p := src.Passages[a.passage]
+		rep.Coverage = append(rep.Coverage, CoverageSpan{
+			Start: query[k].start, End: query[end-1].end,
+			SourceID: src.ID, Role: src.Role, SourceStart: p.DocStart, SourceEnd: p.DocEnd,
+		})
+		k = end
+	}
+	for i := range rep.Sources {
+		rep.Sources[i].ContributionPercent = percent(rep.Sources[i].ContributedTokens, len(query))
+	}
+	sort.SliceStable(rep.Sources, func(i, j int) bool {
+		return rep.Sources[i].ContributedTokens > rep.Sources[j].ContributedTokens
+	})
+	rep.ReferencePercent = percent(ref, len(query))
+	rep.PeerPercent = percent(peer, len(query))
+	rep.CopiedPercent = percent(ref+peer, len(query))
+	rep.OriginalityPercent = math.Round(10*(100-rep.CopiedPercent)) / 10
+	return rep, nil
+}
+
+type attribution struct{ source, passage int }
+
+// attribute picks the best source of every query token: the longest passage
+// covering it wins, then reference material over peers, then the source
+// covering more of the query overall.
+func attribute(tokens int, sources []OriginalitySource) []*attribution {
+	best := make([]*attribution, tokens)
+	better := func(a, b *attribution) bool {
+		pa, pb := sources[a.source].Passages[a.passage], sources[b.source].Passages[b.passage]
+		if la, lb := pa.to-pa.from, pb.to-pb.from; la != lb {
+			return la > lb
+		}
+		ra, rb := sources[a.source].Role == domain.RoleReference, sources[b.source].Role == domain.RoleReference
+		if ra != rb {
+			return ra
+		}
+		return sources[a.source].MatchedTokens > sources[b.source].MatchedTokens
+	}
+	for s := range sources {
+		for p, pass := range sources[s].Passages {
+			a := &attribution{source: s, passage: p}
+			for k := pass.from; k < pass.to; k++ {
+				if best[k] == nil || better(a, best[k]) {
+					best[k] = a
+				}
+			}
+		}
+	}
+	return best
+}
+
+func percent(n, total int) float64 {
+	return math.Round(1000*float64(n)/float64(total)) / 10
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+// IndexVersion is bumped whenever the stored text layer or the index built
+// from it changes shape, so that every document is reindexed.
+const IndexVersion = "1"
+
+// pipeline stamps the versions that produced a document's text. The
+// obfuscation pass rewrites text before normalization, so it is part of the
+// normalizer stage.
+func (u *Ingest) pipeline(ex ports.Extractor) *domain.PipelineVersion {
+	return &domain.PipelineVersion{
+		Extractor:  componentVersion(ex),
+		Normalizer: componentVersion(u.norm) + "+" + componentVersion(u.obfuscation),
+		Index:      IndexVersion,
+	}
+}
+
+// CurrentPipeline is the stamp a document of this extension would get now,
+// or nil when no extractor handles it.
+func (u *Ingest) CurrentPipeline(ext string) *domain.PipelineVersion {
+	ex := u.extractors.For(ext)
+	if ex == nil {
+		return nil
+	}
+	return u.pipeline(ex)
+}
+
+// IsStale reports whether doc was produced by an older pipeline. The text
+// of a reviewer-corrected document does not come from the extractor, so
+// only its normalizer and index versions count.
+func (u *Ingest) IsStale(doc domain.Document) bool {
+	cur := u.CurrentPipeline(doc.Ext)
+	if doc.Pipeline == nil || cur == nil {
+		return true
+	}
+	if doc.Correction != nil {
+		cur.Extractor = doc.Pipeline.Extractor
+	}
+	return *doc.Pipeline != *cur
+}
+
+// componentVersion is "TypeName@version"; components that do not implement
+// ports.Versioned report version 0.
+func componentVersion(c any) string {
+	v := "0"
+	if vc, ok := c.(ports.Versioned); ok {
+		v = vc.Version()
+	}
+	name := fmt.Sprintf("%T", c)
+	return name[strings.LastIndexByte(name, '.')+1:] + "@" + v
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+// quarantineFailed stores an upload whose extraction failed and returns err
+// annotated with the quarantine ID. A failed retry keeps the original
+// CreatedAt and bumps Attempts.
+func (u *Ingest) quarantineFailed(doc domain.Document, extractor string, data []byte, err error) error {
+	now := time.Now()
+	item := domain.QuarantineItem{
+		ID:               doc.ID,
+		Folder:           doc.Folder,
+		Owner:            doc.Owner,
+		OwnerVerified:    doc.OwnerVerified,
+		Role:             doc.Role,
+		OriginalFilename: doc.OriginalFilename,
+		Ext:              doc.Ext,
+		MimeType:         doc.MimeType,
+		Size:             doc.Size,
+		Extractor:        extractor,
+		Error:            err.Error(),
+		Attempts:         1,
+		CreatedAt:        now,
+		LastAttemptAt:    now,
+	}
+	var tool *ports.ToolError
+	if errors.As(err, &tool) {
+		item.Stderr = tool.Stderr
+	}
+	if prev, perr := u.quarantine.Get(doc.ID); perr == nil {
+		item.CreatedAt, item.Attempts = prev.CreatedAt, prev.Attempts+1
+	}
+	if qerr := u.quarantine.Save(item, data); qerr != nil {
+		log.Printf("Ingest: quarantining %s: %v", doc.ID, qerr)
+		return err
+	}
+	log.Printf("Ingest: %s (%s) quarantined after %d attempt(s): %v", doc.ID, doc.OriginalFilename, item.Attempts, err)
+	return fmt.Errorf("%w (quarantined as %s)", err, doc.ID)
+}
+
+// extractorName is the extractor's type name, e.g. "PDFToTextExtractor".
+func extractorName(ex ports.Extractor) string {
+	name := fmt.Sprintf("%T", ex)
+	return name[strings.LastIndexByte(name, '.')+1:]
+}
+
+// extractionCounters counts extraction attempts and failures per extractor
+// since the process started.
+type extractionCounters struct {
+	mu sync.Mutex
+	m  map[string]*[2]int64
+}
+
+func newExtractionCounters() *extractionCounters {
+	return &extractionCounters{m: map[string]*[2]int64{}}
+}
+
+func (c *extractionCounters) record(name string, failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n, ok := c.m[name]
+	if !ok {
+		n = &[2]int64{}
+		c.m[name] = n
+	}
+	n[0]++
+	if failed {
+		n[1]++
+	}
+}
+
+// ExtractorStats is the failure rate of one extractor. Attempts and Failures
+// cover the current process; Quarantined counts the items waiting on disk.
+type ExtractorStats struct {
+	Extractor   string  `json:"extractor"`
+	Attempts    int64   `json:"attempts"`
+	Failures    int64   `json:"failures"`
+	FailureRate float64 `json:"failureRate"`
+	Quarantined int     `json:"quarantined"`
+}
+
+// Quarantine lists, retries and purges uploads whose extraction failed.
+type Quarantine struct {
+	repo   ports.QuarantineRepo
+	ingest *Ingest
+}
+
+func NewQuarantine(repo ports.QuarantineRepo, ingest *Ingest) *Quarantine {
+	return &Quarantine{repo: repo, ingest: ingest}
+}
+
+func (q *Quarantine) List() ([]domain.QuarantineItem, error) { return q.repo.List() }
+
+func (q *Quarantine) Get(id string) (domain.QuarantineItem, error) { return q.repo.Get(id) }
+
+// Retry runs the item through ingest again under its original ID. On success
+// the item leaves the quarantine; on failure it stays with Attempts bumped.
+func (q *Quarantine) Retry(ctx context.Context, id string) (domain.Document, error) {
+	item, err := q.repo.Get(id)
+	if err != nil {
+		return domain.Document{}, err
+	}
+	data, err := q.repo.Data(id)
+	if err != nil {
+		return domain.Document{}, err
+	}
+	doc, err := q.ingest.SaveAndIndex(ctx, domain.Document{ID: item.ID, Folder: item.Folder, OriginalFilename: item.OriginalFilename, Owner: item.Owner, OwnerVerified: item.OwnerVerified, Role: item.Role}, data)
+	if err != nil {
+		return doc, err
+	}
+	if err := q.repo.Delete(id); err != nil {
+		log.Printf("Quarantine: removing recovered %s: %v", id, err)
+	}
+	log.Printf("Quarantine: %s recovered", id)
+	return doc, nil
+}
+
+// RetryAll retries every item one after the other, oldest first.
+func (q *Quarantine) RetryAll(ctx context.Context) ([]BatchResult, error) {
+	items, err := q.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]BatchResult, len(items))
+	for i, item := range items {
+		results[i] = BatchResult{OriginalFilename: item.OriginalFilename, ID: item.ID}
+		if ctx.Err() != nil {
+			results[i].Error = ctx.Err().Error()
+			continue
+		}
+		doc, err := q.Retry(ctx, item.ID)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Document = &doc
+	}
+	return results, nil
+}
+
+func (q *Quarantine) Purge(id string) error { return q.repo.Delete(id) }
+
+// PurgeOlderThan deletes items quarantined more than age ago; zero purges all.
+func (q *Quarantine) PurgeOlderThan(age time.Duration) (int, error) {
+	items, err := q.repo.List()
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-age)
+	n := 0
+	for _, item := range items {
+		if age > 0 && item.CreatedAt.After(cutoff) {
+			continue
+		}
+		if err := q.repo.Delete(item.ID); err != nil {
+			log.Printf("Quarantine: purging %s: %v", item.ID, err)
+			continue
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Metrics reports the failure rate of every extractor that ran or still has
+// quarantined items, sorted by name.
+func (q *Quarantine) Metrics() ([]ExtractorStats, error) {
+	items, err := q.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]*ExtractorStats{}
+	get := func(name string) *ExtractorStats {
+		s, ok := byName[name]
+		if !ok {
+			s = &ExtractorStats{Extractor: name}
+			byName[name] = s
+		}
+		return s
+	}
+	c := q.ingest.stats
+	c.mu.Lock()
+	for name, n := range c.m {
+		s := get(name)
+		s.Attempts, s.Failures = n[0], n[1]
+		s.FailureRate = float64(n[1]) / float64(n[0])
+	}
+	c.mu.Unlock()
+	for _, item := range items {
+		get(item.Extractor).Quarantined++
+	}
+	out := make([]ExtractorStats, 0, len(byName))
+	for _, s := range byName {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Extractor < out[j].Extractor })
+	return out, nil
+}
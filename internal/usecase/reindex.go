@@ -0,0 +1,419 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"detector_plagio/backend/internal/config"
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+	"github.com/google/uuid"
+)
+
+const (
+	ReindexRunning    = "running"
+	ReindexPaused     = "paused"
+	ReindexCommitting = "committing"
+	ReindexCompleted  = "completed"
+)
+
+// maxReindexErrors bounds the failures kept on the job for display.
+const maxReindexErrors = 100
+
+var (
+	ErrReindexRunning = errors.New("a reindex job is already running")
+	ErrNoReindexJob   = errors.New("no reindex job")
+)
+
+type ReindexError struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// ReindexJob is the progress of a reindex, persisted after every document
+// so that an interrupted job resumes after Cursor, the last ID handled.
+type ReindexJob struct {
+	ID         string         `json:"id"`
+	Status     string         `json:"status"`
+	Force      bool           `json:"force"`
+	ThrottleMS int64          `json:"throttleMs"`
+	Total      int            `json:"total"`
+	Processed  int            `json:"processed"`
+	Reindexed  int            `json:"reindexed"`
+	Skipped    int            `json:"skipped"`
+	Failed     int            `json:"failed"`
+	Cursor     string         `json:"cursor"`
+	Errors     []ReindexError `json:"errors"`
+	StartedAt  time.Time      `json:"startedAt"`
+	UpdatedAt  time.Time      `json:"updatedAt"`
+	FinishedAt *time.Time     `json:"finishedAt,omitempty"`
+}
+
+// stagedDoc is a reindexed document waiting for the commit, together with
+// the fingerprint of the version it replaces.
+type stagedDoc struct {
+	SourceHash string          `json:"sourceHash"`
+	Doc        domain.Document `json:"doc"`
+}
+
+// fingerprint hashes everything stored for a document. UpdatedAt alone has
+// one-second resolution and would miss an edit made in the same second.
+func fingerprint(doc domain.Document) string {
+	b, _ := json.Marshal(doc)
+	return checksum(b)
+}
+
+// Reindexer re-extracts stored documents under the current pipeline. New
+// versions are staged under <DataRoot>/reindex and only replace the live
+// sidecars once every document is done, so the server keeps comparing
+// against a consistent corpus while the job runs.
+type Reindexer struct {
+	cfg       *config.Config
+	repo      ports.DocumentRepo
+	ingest    *Ingest
+	revisions ports.TextRevisionRepo
+
+	mu     sync.Mutex
+	job    *ReindexJob
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewReindexer(cfg *config.Config, repo ports.DocumentRepo, ingest *Ingest, revisions ports.TextRevisionRepo) *Reindexer {
+	r := &Reindexer{cfg: cfg, repo: repo, ingest: ingest, revisions: revisions}
+	if b, err := os.ReadFile(r.jobPath()); err == nil {
+		var job ReindexJob
+		if err := json.Unmarshal(b, &job); err == nil {
+			r.job = &job
+		}
+	}
+	return r
+}
+
+func (r *Reindexer) jobPath() string   { return filepath.Join(r.cfg.ReindexPath(), "job.json") }
+func (r *Reindexer) stagedDir() string { return filepath.Join(r.cfg.ReindexPath(), "staged") }
+
+// Status returns a copy of the current or last job.
+func (r *Reindexer) Status() (ReindexJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.job == nil {
+		return ReindexJob{}, ErrNoReindexJob
+	}
+	return r.snapshot(), nil
+}
+
+// Start begins a new job, discarding a paused one. Without force only
+// documents stamped with an older pipeline are re-extracted.
+func (r *Reindexer) Start(force bool, throttle time.Duration) (ReindexJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return r.snapshot(), ErrReindexRunning
+	}
+	if err := os.RemoveAll(r.stagedDir()); err != nil {
+		return ReindexJob{}, err
+	}
+	if err := os.MkdirAll(r.stagedDir(), 0755); err != nil {
+		return ReindexJob{}, err
+	}
+	now := time.Now()
+	r.job = &ReindexJob{ID: uuid.NewString(), Status: ReindexRunning, Force: force, ThrottleMS: throttle.Milliseconds(), Errors: []ReindexError{}, StartedAt: now, UpdatedAt: now}
+	r.launch()
+	return r.snapshot(), nil
+}
+
+// Resume continues a paused or interrupted job after its cursor.
+func (r *Reindexer) Resume() (ReindexJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return r.snapshot(), ErrReindexRunning
+	}
+	if r.job == nil || r.job.Status == ReindexCompleted {
+		return ReindexJob{}, ErrNoReindexJob
+	}
+	if r.job.Status == ReindexPaused {
+		r.job.Status = ReindexRunning
+	}
+	r.launch()
+	return r.snapshot(), nil
+}
+
+// ResumeInterrupted restarts a job that was running when the server stopped.
+func (r *Reindexer) ResumeInterrupted() {
+	r.mu.Lock()
+	interrupted := r.job != nil && (r.job.Status == ReindexRunning || r.job.Status == ReindexCommitting)
+	var jobID string
+	if interrupted {
+		jobID = r.job.ID
+	}
+	r.mu.Unlock()
+	if interrupted {
+		log.Printf("Reindexer: resuming interrupted job %s", jobID)
+		if _, err := r.Resume(); err != nil {
+			log.Printf("Reindexer: %v", err)
+		}
+	}
+}
+
+// Stop pauses the running job at the current document; Resume continues it.
+func (r *Reindexer) Stop() (ReindexJob, error) {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.mu.Unlock()
+	if cancel == nil {
+		return ReindexJob{}, ErrNoReindexJob
+	}
+	cancel()
+	<-done
+	return r.Status()
+}
+
+// Abort stops the job and throws away everything it staged.
+func (r *Reindexer) Abort() error {
+	if _, err := r.Stop(); err != nil && !errors.Is(err, ErrNoReindexJob) {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.job = nil
+	return os.RemoveAll(r.cfg.ReindexPath())
+}
+
+// launch runs the job in the background; r.mu must be held.
+func (r *Reindexer) launch() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel, r.done = cancel, make(chan struct{})
+	go func() {
+		defer close(r.done)
+		r.run(ctx)
+		r.mu.Lock()
+		r.cancel = nil
+		r.mu.Unlock()
+	}()
+}
+
+func (r *Reindexer) run(ctx context.Context) {
+	r.mu.Lock()
+	committing := r.job.Status == ReindexCommitting
+	r.mu.Unlock()
+	if !committing {
+		if !r.extractAll(ctx) {
+			return
+		}
+	}
+	r.commit()
+}
+
+// extractAll stages every document after the cursor and reports whether it
+// got through all of them.
+func (r *Reindexer) extractAll(ctx context.Context) bool {
+	ids, err := r.repo.ListIDs()
+	if err != nil {
+		r.pause(err)
+		return false
+	}
+	sort.Strings(ids)
+	r.mu.Lock()
+	r.job.Total = len(ids)
+	cursor, force, throttle := r.job.Cursor, r.job.Force, time.Duration(r.job.ThrottleMS)*time.Millisecond
+	r.mu.Unlock()
+	log.Printf("Reindexer: %d documents, resuming after %q", len(ids), cursor)
+
+	for _, id := range ids {
+		if id <= cursor {
+			continue
+		}
+		if ctx.Err() != nil {
+			r.pause(nil)
+			return false
+		}
+		outcome, err := r.stage(ctx, id, force)
+		if errors.Is(err, context.Canceled) {
+			r.pause(nil)
+			return false
+		}
+		r.mu.Lock()
+		switch {
+		case err != nil:
+			r.failLocked(id, err)
+		case outcome:
+			r.job.Reindexed++
+		default:
+			r.job.Skipped++
+		}
+		r.job.Processed++
+		r.job.Cursor = id
+		r.saveLocked()
+		r.mu.Unlock()
+		if throttle > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(throttle):
+			}
+		}
+	}
+	return true
+}
+
+// stage re-extracts one document into the staging area. It returns false
+// for documents that are already current or have no sidecar (see Adopt).
+func (r *Reindexer) stage(ctx context.Context, id string, force bool) (bool, error) {
+	doc, err := r.repo.Get(id)
+	if err != nil {
+		return false, nil
+	}
+	if !force && !r.ingest.IsStale(doc) {
+		return false, nil
+	}
+	var next domain.Document
+	if doc.Correction != nil {
+		// keep the reviewer's text; only its normalization is redone
+		text, err := r.revisions.Text(id, doc.Correction.Revision)
+		if err != nil {
+			return false, err
+		}
+		next = doc
+		next.TextContent = r.ingest.norm.Normalize(text)
+		next.Pipeline = r.ingest.CurrentPipeline(doc.Ext)
+		if next.Pipeline != nil && doc.Pipeline != nil {
+			next.Pipeline.Extractor = doc.Pipeline.Extractor
+		}
+	} else {
+		rawPath, _ := r.repo.PathFor(id)
+		data, err := os.ReadFile(rawPath)
+		if err != nil {
+			return false, err
+		}
+		name := doc.OriginalFilename
+		if name == "" {
+			name = doc.Filename
+		}
+		next, err = r.ingest.Process(ctx, domain.Document{ID: id, Folder: doc.Folder, OriginalFilename: name, Owner: doc.Owner, Role: doc.Role}, data)
+		if err != nil {
+			return false, err
+		}
+		next.OriginalFilename = doc.OriginalFilename
+	}
+	b, err := json.Marshal(stagedDoc{SourceHash: fingerprint(doc), Doc: next})
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(filepath.Join(r.stagedDir(), id+".json"), b, 0644)
+}
+
+// commit swaps the staged documents in. A document changed or deleted since
+// it was staged is left as it is now and moves from Reindexed to Failed, so
+// the job shows it still runs on the old pipeline.
+func (r *Reindexer) commit() {
+	r.mu.Lock()
+	r.job.Status = ReindexCommitting
+	r.saveLocked()
+	jobID := r.job.ID
+	r.mu.Unlock()
+
+	ents, err := os.ReadDir(r.stagedDir())
+	if err != nil && !os.IsNotExist(err) {
+		r.pause(err)
+		return
+	}
+	swapped := 0
+	for _, e := range ents {
+		p := filepath.Join(r.stagedDir(), e.Name())
+		id := strings.TrimSuffix(e.Name(), ".json")
+		if err := r.swap(p, id); err != nil {
+			log.Printf("Reindexer: committing %s: %v", id, err)
+			r.mu.Lock()
+			r.job.Reindexed--
+			r.failLocked(id, err)
+			r.saveLocked()
+			r.mu.Unlock()
+		} else {
+			swapped++
+		}
+		os.Remove(p)
+	}
+
+	r.mu.Lock()
+	now := time.Now()
+	r.job.Status, r.job.FinishedAt = ReindexCompleted, &now
+	r.saveLocked()
+	r.mu.Unlock()
+	os.RemoveAll(r.stagedDir())
+	log.Printf("Reindexer: job %s committed %d documents", jobID, swapped)
+}
+
+func (r *Reindexer) swap(stagedPath, id string) error {
+	b, err := os.ReadFile(stagedPath)
+	if err != nil {
+		return err
+	}
+	var s stagedDoc
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	_, err = r.repo.Update(id, func(live *domain.Document) error {
+		if fingerprint(*live) != s.SourceHash {
+			return errors.New("changed while reindexing; left as is")
+		}
+		*live = s.Doc
+		_, txtPath := r.repo.PathFor(id)
+		if err := os.WriteFile(txtPath, []byte(s.Doc.TextContent), 0644); err != nil {
+			return err
+		}
+		return r.ingest.index.Put(s.Doc)
+	})
+	return err
+}
+
+func (r *Reindexer) pause(err error) {
+	if err != nil {
+		log.Printf("Reindexer: pausing: %v", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.job.Status = ReindexPaused
+	r.saveLocked()
+}
+
+// failLocked records a failure of id on the job; r.mu must be held.
+func (r *Reindexer) failLocked(id string, err error) {
+	r.job.Failed++
+	if len(r.job.Errors) < maxReindexErrors {
+		r.job.Errors = append(r.job.Errors, ReindexError{ID: id, Error: err.Error()})
+	}
+}
+
+// saveLocked persists the job; r.mu must be held.
+func (r *Reindexer) saveLocked() {
+	r.job.UpdatedAt = time.Now()
+	b, _ := json.MarshalIndent(r.job, "", "  ")
+	if err := os.MkdirAll(r.cfg.ReindexPath(), 0755); err != nil {
+		log.Printf("Reindexer: %v", err)
+		return
+	}
+	tmp := r.jobPath() + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		log.Printf("Reindexer: saving job: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, r.jobPath()); err != nil {
+		log.Printf("Reindexer: saving job: %v", err)
+	}
+}
+
+// snapshot copies the job; r.mu must be held.
+func (r *Reindexer) snapshot() ReindexJob {
+	job := *r.job
+	job.Errors = append([]ReindexError(nil), r.job.Errors...)
+	return job
+}
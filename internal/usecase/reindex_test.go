@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"detector_plagio/backend/internal/config"
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/service"
+)
+
+// dirRepo is a memRepo whose text layers are written under dir.
+type dirRepo struct {
+	memRepo
+	dir string
+}
+
+func (r dirRepo) PathFor(id string) (string, string) { return "", filepath.Join(r.dir, id+".txt") }
+
+func TestCommitCountsDocumentsChangedDuringJob(t *testing.T) {
+	cfg := &config.Config{DataRoot: t.TempDir()}
+	docs := memRepo{
+		"same":    {ID: "same", TextContent: "viejo"},
+		"changed": {ID: "changed", TextContent: "viejo"},
+	}
+	index, err := service.NewInvertedIndex(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReindexer(cfg, dirRepo{docs, t.TempDir()}, &Ingest{index: index}, nil)
+	if err := os.MkdirAll(r.stagedDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for id, d := range docs {
+		next := d
+		next.TextContent = "nuevo"
+		b, _ := json.Marshal(stagedDoc{SourceHash: fingerprint(d), Doc: next})
+		if err := os.WriteFile(filepath.Join(r.stagedDir(), id+".json"), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.job = &ReindexJob{ID: "job", Status: ReindexRunning, Total: 2, Processed: 2, Reindexed: 2, Errors: []ReindexError{}}
+
+	changed := docs["changed"]
+	changed.Role = domain.RoleReference
+	docs["changed"] = changed
+	r.commit()
+
+	job, _ := r.Status()
+	if job.Status != ReindexCompleted || job.Reindexed != 1 || job.Failed != 1 || len(job.Errors) != 1 || job.Errors[0].ID != "changed" {
+		t.Errorf("job = %+v, want 1 reindexed and changed listed as failed", job)
+	}
+	if docs["same"].TextContent != "nuevo" {
+		t.Errorf("unchanged document was not swapped in: %+v", docs["same"])
+	}
+	if d := docs["changed"]; d.TextContent != "viejo" || d.Role != domain.RoleReference {
+		t.Errorf("changed document was overwritten: %+v", d)
+	}
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+var ErrUnknownReportFormat = errors.New("unknown report format")
+
+// Reports renders the comparison of two documents for export.
+type Reports struct {
+	repo      ports.DocumentRepo
+	compare   *Compare
+	lookup    *Lookup
+	renderers map[string]ports.ReportRenderer
+}
+
+// NewReports takes the renderers by format name, e.g. "html" and "pdf".
+func NewReports(repo ports.DocumentRepo, compare *Compare, lookup *Lookup, renderers map[string]ports.ReportRenderer) *Reports {
+	return &Reports{repo: repo, compare: compare, lookup: lookup, renderers: renderers}
+}
+
+// Render builds the report of id1 against id2 and renders it in format. It
+// returns the output with its content type and file extension.
+func (u *Reports) Render(id1, id2, format string) ([]byte, string, string, error) {
+	renderer, ok := u.renderers[format]
+	if !ok {
+		return nil, "", "", ErrUnknownReportFormat
+	}
+	rep, err := u.Build(id1, id2)
+	if err != nil {
+		return nil, "", "", err
+	}
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, rep); err != nil {
+		return nil, "", "", err
+	}
+	return buf.Bytes(), renderer.ContentType(), renderer.Extension(), nil
+}
+
+// Build gathers the documents, every CompareResult score, the passages
+// shared by both texts and the similar sentence pairs.
+func (u *Reports) Build(id1, id2 string) (domain.ComparisonReport, error) {
+	a, err := u.repo.Get(id1)
+	if err != nil {
+		return domain.ComparisonReport{}, err
+	}
+	b, err := u.repo.Get(id2)
+	if err != nil {
+		return domain.ComparisonReport{}, err
+	}
+	res, err := u.compare.CompareDocs(a, b)
+	if err != nil {
+		return domain.ComparisonReport{}, err
+	}
+	rep := domain.ComparisonReport{GeneratedAt: time.Now(), DocA: a, DocB: b}
+	pct := func(f float64) string { return strconv.FormatFloat(f*100, 'f', 1, 64) + "%" }
+	num := func(f float64) string { return strconv.FormatFloat(f, 'f', 4, 64) }
+	rep.Scores = []domain.ReportScore{
+		{Label: "Final", Value: pct(res.Final)},
+		{Label: "Near duplicate", Value: pct(res.NearDuplicate)},
+		{Label: "Topic similarity", Value: pct(res.TopicSimilarity)},
+		{Label: "Jaccard (shingles)", Value: fmt.Sprintf("%s (%d / %d)", num(res.Jaccard.Score), res.Jaccard.Intersection, res.Jaccard.Union)},
+		{Label: "Cosine TF-IDF", Value: fmt.Sprintf("%s (dot %s, |A|² %s, |B|² %s)", num(res.Cosine.Score), num(res.Cosine.Dot), num(res.Cosine.Na2), num(res.Cosine.Nb2))},
+		{Label: "Text length A / B", Value: fmt.Sprintf("%d / %d", res.Doc1TextContentLength, res.Doc2TextContentLength)},
+		{Label: "Tokens A / B", Value: fmt.Sprintf("%d / %d", res.Doc1TokensLength, res.Doc2TokensLength)},
+		{Label: "Shingles A / B", Value: fmt.Sprintf("%d / %d", res.Doc1ShinglesLength, res.Doc2ShinglesLength)},
+		{Label: "Matching segments", Value: strconv.Itoa(len(res.MatchingSegments))},
+	}
+	for _, p := range u.lookup.Passages(a, b) {
+		rep.Passages = append(rep.Passages, domain.ReportPassage{AStart: p.QueryStart, AEnd: p.QueryEnd, BStart: p.DocStart, BEnd: p.DocEnd})
+	}
+	// number the passages in reading order of the first document
+	sort.Slice(rep.Passages, func(i, j int) bool { return rep.Passages[i].AStart < rep.Passages[j].AStart })
+	for _, s := range res.MatchingSegments {
+		rep.Segments = append(rep.Segments, domain.ReportSegment{TextA: s.TextA, TextB: s.TextB, Score: s.Score})
+	}
+	return rep, nil
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+// TextRevisions lets a reviewer replace a document's text layer when the
+// extraction is wrong. Every layer is kept; the first override also stores
+// the machine extraction as revision 1 so that it is never lost.
+type TextRevisions struct {
+	repo        ports.DocumentRepo
+	revisions   ports.TextRevisionRepo
+	norm        ports.Normalizer
+	obfuscation ports.ObfuscationAnalyzer
+	textIndex   ports.TextIndex
+}
+
+func NewTextRevisions(repo ports.DocumentRepo, revisions ports.TextRevisionRepo, n ports.Normalizer, ob ports.ObfuscationAnalyzer, idx ports.TextIndex) *TextRevisions {
+	return &TextRevisions{repo: repo, revisions: revisions, norm: n, obfuscation: ob, textIndex: idx}
+}
+
+// Override stores text as a new manual revision, makes it the document's
+// active text and re-indexes the document. The whole update runs under the
+// document's lock, so two overrides cannot both store the extraction or
+// drop each other's revision.
+func (u *TextRevisions) Override(id, text, userID, userName, note string) (domain.Document, error) {
+	if strings.TrimSpace(text) == "" {
+		return domain.Document{}, errors.New("text is empty")
+	}
+	var rev domain.TextRevision
+	doc, err := u.repo.Update(id, func(doc *domain.Document) error {
+		revs, err := u.revisions.List(id)
+		if err != nil {
+			return err
+		}
+		if len(revs) == 0 {
+			// only the normalized extraction survives ingest; keep that
+			extracted := domain.TextRevision{Source: domain.TextSourceExtraction, Chars: utf8.RuneCountInString(doc.TextContent), CreatedAt: parseUpdatedAt(doc.UpdatedAt)}
+			if _, err := u.revisions.Add(id, extracted, doc.TextContent); err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		rev, err = u.revisions.Add(id, domain.TextRevision{
+			Source:     domain.TextSourceManual,
+			Author:     userID,
+			AuthorName: userName,
+			Note:       note,
+			Chars:      utf8.RuneCountInString(text),
+			CreatedAt:  now,
+		}, text)
+		if err != nil {
+			return err
+		}
+
+		// pasted text goes through the same homoglyph and zero-width
+		// cleanup as an upload before the normalizer drops those characters
+		canonical, _ := u.obfuscation.Analyze(text, nil, "")
+		doc.TextContent = u.norm.Normalize(canonical)
+		// the integrity findings describe the submitted file and still hold;
+		// only the low-text flag and the text stats were about the extraction
+		if doc.Integrity != nil {
+			doc.Integrity.Flags = slices.DeleteFunc(doc.Integrity.Flags, func(f string) bool { return f == domain.FlagLowText })
+		}
+		if doc.TextStats != nil {
+			pages := max(1, doc.TextStats.Pages)
+			chars := countChars(canonical)
+			doc.TextStats = &domain.TextStats{Pages: pages, Chars: chars, CharsPerPage: chars / pages}
+		}
+		doc.Correction = &domain.Correction{Revision: rev.Revision, By: userID, ByName: userName, At: now, Note: note}
+		return u.index(*doc)
+	})
+	if err != nil {
+		return doc, err
+	}
+	log.Printf("TextRevisions: %s corrected by %s (revision %d)", id, userName, rev.Revision)
+	return doc, nil
+}
+
+func (u *TextRevisions) List(id string) ([]domain.TextRevision, error) {
+	if _, err := u.repo.Get(id); err != nil {
+		return nil, err
+	}
+	return u.revisions.List(id)
+}
+
+func (u *TextRevisions) Text(id string, revision int) (string, error) {
+	return u.revisions.Text(id, revision)
+}
+
+// Delete drops the revisions of a deleted document, reviewer texts included.
+func (u *TextRevisions) Delete(id string) error { return u.revisions.Delete(id) }
+
+// index rewrites the stored text layer that comparison reads; the sidecar
+// is saved by the repo update that calls it.
+func (u *TextRevisions) index(doc domain.Document) error {
+	_, txtPath := u.repo.PathFor(doc.ID)
+	if err := os.WriteFile(txtPath, []byte(doc.TextContent), 0644); err != nil {
+		return err
+	}
+	return u.textIndex.Put(doc)
+}
+
+func parseUpdatedAt(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Now()
+	}
+	return t
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+
+	"detector_plagio/backend/internal/config"
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+	"detector_plagio/backend/internal/repo"
+	"detector_plagio/backend/internal/service"
+)
+
+func newRevisionsFixture(t *testing.T, doc domain.Document) (*TextRevisions, ports.DocumentRepo) {
+	t.Helper()
+	cfg := &config.Config{DataRoot: t.TempDir(), AllowedExtMap: map[string]bool{".docx": true}}
+	for _, dir := range []string{cfg.DocsPath(), cfg.TextsPath()} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	docs := repo.NewFSRepo(cfg)
+	if err := docs.Save(doc, []byte("raw")); err != nil {
+		t.Fatal(err)
+	}
+	revs, err := repo.NewFSTextRevisionRepo(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := service.NewInvertedIndex(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewTextRevisions(docs, revs, service.NewNormalizer(), service.NewObfuscationDetector(), index), docs
+}
+
+func TestOverrideKeepsFileFindings(t *testing.T) {
+	u, docs := newRevisionsFixture(t, domain.Document{
+		ID: "doc", Ext: ".docx", TextContent: "texto extraido",
+		Integrity: &domain.Integrity{HiddenRuns: 2, HiddenChars: 40, Flags: []string{domain.FlagHiddenText, domain.FlagLowText}},
+		TextStats: &domain.TextStats{Pages: 2, Chars: 10, CharsPerPage: 5},
+	})
+	// the Cyrillic "о" in "tоdo" must be mapped back before normalizing
+	doc, err := u.Override("doc", "Texto corregido de tоdo el trabajo", "u1", "Ana", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "texto corregido de todo el trabajo"; doc.TextContent != want {
+		t.Errorf("TextContent = %q, want %q", doc.TextContent, want)
+	}
+	if doc.Integrity == nil || doc.Integrity.HiddenRuns != 2 || !slices.Equal(doc.Integrity.Flags, []string{domain.FlagHiddenText}) {
+		t.Errorf("Integrity = %+v, want the hidden-text finding without low-text", doc.Integrity)
+	}
+	if stored, _ := docs.Get("doc"); stored.Correction == nil || stored.Integrity == nil {
+		t.Errorf("stored sidecar = %+v", stored)
+	}
+}
+
+func TestOverrideConcurrent(t *testing.T) {
+	u, docs := newRevisionsFixture(t, domain.Document{ID: "doc", Ext: ".docx", TextContent: "texto extraido"})
+	const n = 32
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := u.Override("doc", fmt.Sprintf("revision %d", i), "u1", "Ana", ""); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	revs, err := u.List("doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	extracted := 0
+	for _, r := range revs {
+		if r.Source == domain.TextSourceExtraction {
+			extracted++
+		}
+	}
+	if len(revs) != n+1 || extracted != 1 {
+		t.Fatalf("got %d revisions with %d extractions, want %d with 1", len(revs), extracted, n+1)
+	}
+	doc, _ := docs.Get("doc")
+	if doc.Correction == nil || doc.Correction.Revision != revs[len(revs)-1].Revision {
+		t.Errorf("active correction = %+v, want the last revision %d", doc.Correction, revs[len(revs)-1].Revision)
+	}
+}
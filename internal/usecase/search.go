@@ -0,0 +1,283 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"strings"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+)
+
+const (
+	maxSnippetsPerHit = 5
+	snippetContext    = 8 // tokens shown on each side of a match
+)
+
+type SearchSnippet struct {
+	// Start and End are rune offsets of the match in the document's textContent.
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	Snippet string `json:"snippet"`
+	// HighlightStart and HighlightEnd are rune offsets of the match in Snippet.
+	HighlightStart int `json:"highlightStart"`
+	HighlightEnd   int `json:"highlightEnd"`
+}
+
+type SearchHit struct {
+	ID               string          `json:"id"`
+	Folder           string          `json:"folder"`
+	OriginalFilename string          `json:"originalFilename"`
+	Matches          int             `json:"matches"`
+	Snippets         []SearchSnippet `json:"snippets"`
+}
+
+type SearchResult struct {
+	Query string      `json:"query"`
+	Total int         `json:"total"`
+	Hits  []SearchHit `json:"hits"`
+}
+
+// span is a match of n tokens starting at token position start.
+type span struct{ start, n int }
+
+type searchOperand struct {
+	terms  []string
+	negate bool
+}
+
+// Search answers phrase and boolean queries from the inverted index.
+type Search struct {
+	repo  ports.DocumentRepo
+	norm  ports.Normalizer
+	index ports.TextIndex
+}
+
+func NewSearch(repo ports.DocumentRepo, n ports.Normalizer, index ports.TextIndex) *Search {
+	return &Search{repo: repo, norm: n, index: index}
+}
+
+// Sync brings the index in line with the stored documents: anything
+// indexed with an outdated text is indexed again and deleted documents are
+// dropped. It covers updates lost when the server stopped before a flush,
+// and must finish before ingestion starts: it indexes the documents as
+// listed, and a newer version indexed meanwhile would be overwritten.
+func (s *Search) Sync() error {
+	docs, err := s.repo.List()
+	if err != nil {
+		return err
+	}
+	live := make(map[string]bool, len(docs))
+	put, removed := 0, 0
+	for _, d := range docs {
+		live[d.ID] = true
+		if !s.index.Current(d) {
+			if err := s.index.Put(d); err != nil {
+				return err
+			}
+			put++
+		}
+	}
+	for _, id := range s.index.IDs() {
+		if !live[id] {
+			if err := s.index.Delete(id); err != nil {
+				return err
+			}
+			removed++
+		}
+	}
+	log.Printf("Search: index synced, %d documents indexed, %d removed", put, removed)
+	return nil
+}
+
+func (s *Search) Unindex(id string) error { return s.index.Delete(id) }
+
+// Query runs q against the index. Space-separated terms must all match; OR
+// between two operands accepts either; NOT or a leading "-" excludes;
+// "double quotes" match an exact phrase. Matching ignores case and accents.
+// With folders set, only documents in one of them are returned, and only
+// documents with one of roles are returned at all.
+func (s *Search) Query(q string, folders []string, roles map[string]bool, limit int) (SearchResult, error) {
+	res := SearchResult{Query: q, Hits: []SearchHit{}}
+	groups, negated := s.parseQuery(q)
+	if len(groups) == 0 {
+		return res, errors.New("query has no terms to search for")
+	}
+
+	var hits map[string][]span
+	for _, g := range groups {
+		matched := map[string][]span{}
+		for _, op := range g {
+			for id, sp := range s.match(op.terms) {
+				matched[id] = append(matched[id], sp...)
+			}
+		}
+		if hits == nil {
+			hits = matched
+			continue
+		}
+		for id := range hits {
+			if sp, ok := matched[id]; ok {
+				hits[id] = append(hits[id], sp...)
+			} else {
+				delete(hits, id)
+			}
+		}
+	}
+	for _, op := range negated {
+		for id := range s.match(op.terms) {
+			delete(hits, id)
+		}
+	}
+	if len(folders) > 0 {
+		allowed := map[string]bool{}
+		for _, f := range folders {
+			allowed[f] = true
+		}
+		for id := range hits {
+			if f, _ := s.index.Folder(id); !allowed[f] {
+				delete(hits, id)
+			}
+		}
+	}
+
+	// roles change without reindexing, so they are read from the sidecars
+	docs := map[string]domain.Document{}
+	for id := range hits {
+		doc, err := s.repo.Get(id)
+		if err != nil || !roles[doc.CorpusRole()] {
+			delete(hits, id)
+			continue
+		}
+		docs[id] = doc
+	}
+
+	ids := make([]string, 0, len(hits))
+	for id := range hits {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if len(hits[ids[i]]) != len(hits[ids[j]]) {
+			return len(hits[ids[i]]) > len(hits[ids[j]])
+		}
+		return ids[i] < ids[j]
+	})
+	res.Total = len(ids)
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+	for _, id := range ids {
+		res.Hits = append(res.Hits, s.hit(docs[id], hits[id]))
+	}
+	return res, nil
+}
+
+// parseQuery returns the positive operands grouped by OR, and the negated ones.
+func (s *Search) parseQuery(q string) ([][]searchOperand, []searchOperand) {
+	var groups [][]searchOperand
+	var negated []searchOperand
+	or, not := false, false
+	add := func(text string) {
+		op := searchOperand{negate: not}
+		// stored texts went through the Normalizer, which drops the letters
+		// it does not keep (façade is stored as "fa ade"); queries must too
+		for _, t := range s.index.Tokens(s.norm.Normalize(text)) {
+			op.terms = append(op.terms, t.Term)
+		}
+		switch {
+		case len(op.terms) == 0:
+		case op.negate:
+			negated = append(negated, op)
+		case or && len(groups) > 0:
+			groups[len(groups)-1] = append(groups[len(groups)-1], op)
+		default:
+			groups = append(groups, []searchOperand{op})
+		}
+		or, not = false, false
+	}
+	for rest := strings.TrimSpace(q); rest != ""; rest = strings.TrimSpace(rest) {
+		if strings.HasPrefix(rest, "-") {
+			not, rest = true, rest[1:]
+			continue
+		}
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				add(rest[1:])
+				break
+			}
+			add(rest[1 : end+1])
+			rest = rest[end+2:]
+			continue
+		}
+		word, tail, _ := strings.Cut(rest, " ")
+		rest = tail
+		switch word {
+		case "AND":
+		case "OR":
+			or = true
+		case "NOT":
+			not = true
+		default:
+			add(word)
+		}
+	}
+	return groups, negated
+}
+
+// match finds terms as a contiguous phrase; a single term is a phrase of one.
+func (s *Search) match(terms []string) map[string][]span {
+	out := map[string][]span{}
+	postings := make([]map[string][]int, len(terms))
+	for i, t := range terms {
+		postings[i] = s.index.Postings(t)
+		if len(postings[i]) == 0 {
+			return out
+		}
+	}
+	for id, first := range postings[0] {
+	next:
+		for _, p := range first {
+			for i := 1; i < len(terms); i++ {
+				pos := postings[i][id]
+				k := sort.SearchInts(pos, p+i)
+				if k == len(pos) || pos[k] != p+i {
+					continue next
+				}
+			}
+			out[id] = append(out[id], span{start: p, n: len(terms)})
+		}
+	}
+	return out
+}
+
+func (s *Search) hit(doc domain.Document, spans []span) SearchHit {
+	h := SearchHit{ID: doc.ID, Folder: doc.Folder, OriginalFilename: doc.OriginalFilename, Matches: len(spans), Snippets: []SearchSnippet{}}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	toks := s.index.Tokens(doc.TextContent)
+	text := []rune(doc.TextContent)
+	for _, sp := range spans {
+		if len(h.Snippets) == maxSnippetsPerHit {
+			break
+		}
+		if sp.start+sp.n > len(toks) {
+			continue // text changed since it was indexed
+		}
+		from := max(0, sp.start-snippetContext)
+		to := min(len(toks), sp.start+sp.n+snippetContext)
+		snip := SearchSnippet{Start: toks[sp.start].Start, End: toks[sp.start+sp.n-1].End}
+		prefix := ""
+		if from > 0 {
+			prefix = "…"
+		}
+		snip.Snippet = prefix + string(text[toks[from].Start:toks[to-1].End])
+		if to < len(toks) {
+			snip.Snippet += "…"
+		}
+		shift := len([]rune(prefix)) - toks[from].Start
+		snip.HighlightStart, snip.HighlightEnd = snip.Start+shift, snip.End+shift
+		h.Snippets = append(h.Snippets, snip)
+	}
+	return h
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+	"detector_plagio/backend/internal/service"
+)
+
+// memRepo is an in-memory DocumentRepo holding documents already ingested.
+type memRepo map[string]domain.Document
+
+func (m memRepo) Save(doc domain.Document, data []byte) error { m[doc.ID] = doc; return nil }
+func (m memRepo) SaveMeta(doc domain.Document) error          { m[doc.ID] = doc; return nil }
+func (m memRepo) PathFor(id string) (string, string)          { return "", "" }
+func (m memRepo) Delete(id string) error                      { delete(m, id); return nil }
+
+func (m memRepo) Update(id string, fn func(*domain.Document) error) (domain.Document, error) {
+	d, err := m.Get(id)
+	if err != nil {
+		return d, err
+	}
+	if err := fn(&d); err != nil {
+		return d, err
+	}
+	m[id] = d
+	return d, nil
+}
+
+func (m memRepo) Get(id string) (domain.Document, error) {
+	d, ok := m[id]
+	if !ok {
+		return d, os.ErrNotExist
+	}
+	return d, nil
+}
+
+func (m memRepo) List() ([]domain.Document, error) {
+	out := []domain.Document{}
+	for _, id := range m.ids() {
+		out = append(out, m[id])
+	}
+	return out, nil
+}
+
+func (m memRepo) ListIDs() ([]string, error) { return m.ids(), nil }
+
+func (m memRepo) ListFolders() ([]string, error) {
+	seen := map[string]bool{}
+	var out []string
+	for _, d := range m {
+		if !seen[d.Folder] {
+			seen[d.Folder] = true
+			out = append(out, d.Folder)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (m memRepo) ids() []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// corpus normalizes and stores texts, keyed by ID, in folder "a".
+func corpus(t *testing.T, texts map[string]string) (memRepo, ports.Normalizer, ports.TextIndex) {
+	t.Helper()
+	norm := service.NewNormalizer()
+	index, err := service.NewInvertedIndex(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := memRepo{}
+	for id, text := range texts {
+		doc := domain.Document{ID: id, Folder: "a", OriginalFilename: id + ".txt", TextContent: norm.Normalize(text)}
+		repo[id] = doc
+		if err := index.Put(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo, norm, index
+}
+
+// searchable are the roles searched by default.
+var searchable = map[string]bool{domain.RoleReference: true, domain.RoleSubmission: true}
+
+func TestParseQuery(t *testing.T) {
+	_, norm, index := corpus(t, nil)
+	s := NewSearch(memRepo{}, norm, index)
+	cases := []struct {
+		q       string
+		groups  [][]searchOperand
+		negated []searchOperand
+	}{
+		{"Plantas verdes", [][]searchOperand{{{terms: []string{"plantas"}}}, {{terms: []string{"verdes"}}}}, nil},
+		{`"Plantas  Verdes" día`, [][]searchOperand{{{terms: []string{"plantas", "verdes"}}}, {{terms: []string{"dia"}}}}, nil},
+		{"sol OR luna AND mar", [][]searchOperand{{{terms: []string{"sol"}}, {terms: []string{"luna"}}}, {{terms: []string{"mar"}}}}, nil},
+		{"sol NOT luna -mar", [][]searchOperand{{{terms: []string{"sol"}}}}, []searchOperand{{terms: []string{"luna"}, negate: true}, {terms: []string{"mar"}, negate: true}}},
+		{`-"luna llena" sol`, [][]searchOperand{{{terms: []string{"sol"}}}}, []searchOperand{{terms: []string{"luna", "llena"}, negate: true}}},
+		{`"sin cerrar`, [][]searchOperand{{{terms: []string{"sin", "cerrar"}}}}, nil},
+		{"façade", [][]searchOperand{{{terms: []string{"fa", "ade"}}}}, nil},
+		{"¿? -- OR", nil, nil},
+	}
+	for _, c := range cases {
+		groups, negated := s.parseQuery(c.q)
+		if !reflect.DeepEqual(groups, c.groups) || !reflect.DeepEqual(negated, c.negated) {
+			t.Errorf("parseQuery(%q) = %+v, %+v; want %+v, %+v", c.q, groups, negated, c.groups, c.negated)
+		}
+	}
+}
+
+func TestSearchQuery(t *testing.T) {
+	repo, norm, index := corpus(t, map[string]string{
+		"d1": "El proceso de fotosíntesis ocurre en los cloroplastos de las plantas.",
+		"d2": "Las plantas verdes realizan la FOTOSÍNTESIS durante el día.",
+		"d3": "La façade del edificio mira al norte.",
+	})
+	d2 := repo["d2"]
+	d2.Folder = "b"
+	repo["d2"] = d2
+	if err := index.Put(d2); err != nil {
+		t.Fatal(err)
+	}
+	d3 := repo["d3"]
+	d3.Role = domain.RoleExcluded
+	repo["d3"] = d3
+	s := NewSearch(repo, norm, index)
+	cases := []struct {
+		q       string
+		folders []string
+		roles   map[string]bool
+		want    []string
+	}{
+		{"fotosintesis", nil, searchable, []string{"d1", "d2"}},
+		{`"plantas verdes"`, nil, searchable, []string{"d2"}},
+		{`"verdes plantas"`, nil, searchable, []string{}},
+		{"cloroplastos OR verdes", nil, searchable, []string{"d1", "d2"}},
+		{"plantas -verdes", nil, searchable, []string{"d1"}},
+		{"plantas NOT verdes", nil, searchable, []string{"d1"}},
+		{"fotosíntesis", []string{"a"}, searchable, []string{"d1"}},
+		{"Façade", nil, searchable, []string{}},
+		{"Façade", nil, map[string]bool{domain.RoleExcluded: true}, []string{"d3"}},
+	}
+	for _, c := range cases {
+		res, err := s.Query(c.q, c.folders, c.roles, 10)
+		if err != nil {
+			t.Errorf("Query(%q): %v", c.q, err)
+			continue
+		}
+		got := []string{}
+		for _, h := range res.Hits {
+			got = append(got, h.ID)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, c.want) || res.Total != len(c.want) {
+			t.Errorf("Query(%q, %v) = %v (total %d), want %v", c.q, c.folders, got, res.Total, c.want)
+		}
+	}
+	if _, err := s.Query("-plantas", nil, searchable, 10); err == nil {
+		t.Error("a query with only negated terms should fail")
+	}
+}
+
+func TestSearchSnippetOffsets(t *testing.T) {
+	repo, norm, index := corpus(t, map[string]string{
+		"d1": "uno dos tres cuatro cinco seis siete ocho nueve diez once doce trece catorce quince dieciséis diecisiete dieciocho",
+	})
+	res, err := NewSearch(repo, norm, index).Query(`"doce trece"`, nil, searchable, 10)
+	if err != nil || len(res.Hits) != 1 || len(res.Hits[0].Snippets) != 1 {
+		t.Fatalf("Query = %+v, %v", res, err)
+	}
+	sn := res.Hits[0].Snippets[0]
+	text := []rune(repo["d1"].TextContent)
+	if got := string(text[sn.Start:sn.End]); got != "doce trece" {
+		t.Errorf("text[Start:End] = %q", got)
+	}
+	snippet := []rune(sn.Snippet)
+	if got := string(snippet[sn.HighlightStart:sn.HighlightEnd]); got != "doce trece" {
+		t.Errorf("snippet[HighlightStart:HighlightEnd] = %q in %q", got, sn.Snippet)
+	}
+	if want := "…cuatro cinco seis siete ocho nueve diez once doce trece catorce quince dieciséis diecisiete dieciocho"; sn.Snippet != want {
+		t.Errorf("Snippet = %q, want %q", sn.Snippet, want)
+	}
+}
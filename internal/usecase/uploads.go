@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"detector_plagio/backend/internal/config"
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUploadIncomplete = errors.New("upload is not complete")
+	ErrUploadExpired    = errors.New("upload session expired")
+	ErrUploadTooLarge   = errors.New("upload exceeds the resumable size limit")
+	ErrUploadFinalized  = errors.New("upload is already finalized")
+)
+
+// Uploads implements resumable uploads: a session is created with the final
+// length, receives chunks at increasing offsets and is handed to Ingest once
+// every byte has arrived.
+type Uploads struct {
+	cfg    *config.Config
+	repo   ports.UploadRepo
+	ingest *Ingest
+
+	mu         sync.Mutex
+	finalizing map[string]*sync.Mutex
+}
+
+func NewUploads(cfg *config.Config, repo ports.UploadRepo, ingest *Ingest) *Uploads {
+	return &Uploads{cfg: cfg, repo: repo, ingest: ingest, finalizing: map[string]*sync.Mutex{}}
+}
+
+// lock serializes Finalize calls on the same session, e.g. a client retrying
+// while the first call is still ingesting.
+func (u *Uploads) lock(id string) func() {
+	u.mu.Lock()
+	l, ok := u.finalizing[id]
+	if !ok {
+		l = &sync.Mutex{}
+		u.finalizing[id] = l
+	}
+	u.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+func (u *Uploads) forget(id string) {
+	u.mu.Lock()
+	delete(u.finalizing, id)
+	u.mu.Unlock()
+}
+
+// Create opens a session for s, which carries the file name, folder, owner,
+// role and length; the ID, offset and timestamps are assigned here.
+func (u *Uploads) Create(s domain.UploadSession) (domain.UploadSession, error) {
+	if s.Length <= 0 {
+		return domain.UploadSession{}, errors.New("upload length must be positive")
+	}
+	if !domain.ValidRole(s.Role) {
+		return domain.UploadSession{}, fmt.Errorf("unknown role %q", s.Role)
+	}
+	if s.Length > u.cfg.ResumableMaxMB<<20 {
+		return domain.UploadSession{}, ErrUploadTooLarge
+	}
+	now := time.Now()
+	s.ID, s.Offset, s.DocumentID = uuid.NewString(), 0, ""
+	s.CreatedAt, s.ExpiresAt = now, now.Add(u.cfg.UploadSessionTTL)
+	log.Printf("Uploads: created session %s for %s (%d bytes)", s.ID, s.OriginalFilename, s.Length)
+	return s, u.repo.Create(s)
+}
+
+func (u *Uploads) Get(id string) (domain.UploadSession, error) {
+	s, err := u.repo.Get(id)
+	if err != nil {
+		return s, err
+	}
+	if time.Now().After(s.ExpiresAt) {
+		return s, ErrUploadExpired
+	}
+	return s, nil
+}
+
+// Append stores one chunk; the bytes received before a transfer error are
+// kept so that the client can resume from the returned offset.
+func (u *Uploads) Append(id string, offset int64, chunk io.Reader) (domain.UploadSession, error) {
+	s, err := u.Get(id)
+	if err != nil {
+		return domain.UploadSession{}, err
+	}
+	if s.DocumentID != "" {
+		return s, ErrUploadFinalized
+	}
+	return u.repo.Append(id, offset, chunk)
+}
+
+// Finalize ingests the assembled file. The session is marked with the new
+// document instead of being removed, so that repeated calls return that
+// document rather than ingesting the file again. The document takes the
+// session ID, so a retry after a failed extraction bumps the attempts of the
+// same quarantine item instead of quarantining another copy.
+func (u *Uploads) Finalize(ctx context.Context, id string) (domain.Document, error) {
+	defer u.lock(id)()
+	s, err := u.Get(id)
+	if err != nil {
+		return domain.Document{}, err
+	}
+	if s.DocumentID != "" {
+		return u.ingest.repo.Get(s.DocumentID)
+	}
+	if !s.Complete() {
+		return domain.Document{}, fmt.Errorf("%w: %d of %d bytes", ErrUploadIncomplete, s.Offset, s.Length)
+	}
+	data, release, err := u.repo.Open(id)
+	if err != nil {
+		return domain.Document{}, err
+	}
+	doc, err := u.ingest.SaveAndIndex(ctx, domain.Document{ID: s.ID, Folder: s.Folder, OriginalFilename: s.OriginalFilename, Owner: s.Owner, OwnerVerified: s.OwnerVerified, Role: s.Role}, data)
+	if rerr := release(); rerr != nil {
+		log.Printf("Uploads: releasing %s: %v", id, rerr)
+	}
+	if err != nil {
+		return doc, err
+	}
+	if err := u.repo.Finalized(id, doc.ID); err != nil {
+		log.Printf("Uploads: marking session %s finalized: %v", id, err)
+	}
+	if _, err := u.ingest.quarantine.Get(doc.ID); err == nil {
+		if err := u.ingest.quarantine.Delete(doc.ID); err != nil {
+			log.Printf("Uploads: removing quarantined attempt of %s: %v", doc.ID, err)
+		}
+	}
+	return doc, nil
+}
+
+func (u *Uploads) Cancel(id string) error {
+	defer u.forget(id)
+	return u.repo.Delete(id)
+}
+
+// PurgeExpired deletes sessions past their expiry and returns how many went.
+func (u *Uploads) PurgeExpired() (int, error) {
+	sessions, err := u.repo.List()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	now := time.Now()
+	for _, s := range sessions {
+		if now.After(s.ExpiresAt) {
+			if err := u.repo.Delete(s.ID); err != nil {
+				log.Printf("Uploads: purging %s: %v", s.ID, err)
+				continue
+			}
+			u.forget(s.ID)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// RunJanitor purges expired sessions every interval until ctx is done.
+func (u *Uploads) RunJanitor(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if n, err := u.PurgeExpired(); err != nil {
+				log.Printf("Uploads: janitor: %v", err)
+			} else if n > 0 {
+				log.Printf("Uploads: janitor purged %d expired sessions", n)
+			}
+		}
+	}
+}
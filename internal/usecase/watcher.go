@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"detector_plagio/backend/internal/config"
+	"detector_plagio/backend/internal/domain"
+	"detector_plagio/backend/internal/ports"
+	"github.com/google/uuid"
+)
+
+// Processed files are moved under these directories of their inbox.
+const (
+	watchDoneDir   = "done"
+	watchFailedDir = "failed"
+)
+
+const (
+	WatchIngested        = "ingested"
+	WatchAlreadyIngested = "already-ingested"
+	WatchFailed          = "failed"
+)
+
+// watchNamespace seeds the deterministic IDs of hot-folder documents.
+var watchNamespace = uuid.MustParse("4fe4ab2b-8bec-420e-8175-5fda37119cf6")
+
+// WatchLog is the sidecar written next to every processed file.
+type WatchLog struct {
+	File        string    `json:"file"`
+	Folder      string    `json:"folder"`
+	ID          string    `json:"id"`
+	SHA256      string    `json:"sha256"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	ProcessedAt time.Time `json:"processedAt"`
+}
+
+type watchedFile struct {
+	size  int64
+	mod   time.Time
+	since time.Time
+}
+
+// Watcher polls inbox directories and ingests every file once it has stopped
+// changing. <inbox>/<sub>/<file> goes to folder <sub>. The document ID is
+// derived from the file's path and content, so a file seen again after a
+// restart (or a crash before it was moved) is recognized instead of ingested
+// twice.
+type Watcher struct {
+	cfg    *config.Config
+	repo   ports.DocumentRepo
+	ingest *Ingest
+	seen   map[string]watchedFile
+}
+
+func NewWatcher(cfg *config.Config, repo ports.DocumentRepo, ingest *Ingest) *Watcher {
+	return &Watcher{cfg: cfg, repo: repo, ingest: ingest, seen: map[string]watchedFile{}}
+}
+
+// Run scans the inboxes every WatchInterval until ctx is done.
+func (w *Watcher) Run(ctx context.Context) {
+	log.Printf("Watcher: polling %v every %s", w.cfg.WatchDirs, w.cfg.WatchInterval)
+	t := time.NewTicker(w.cfg.WatchInterval)
+	defer t.Stop()
+	for {
+		w.Scan(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Scan makes one pass over every inbox.
+func (w *Watcher) Scan(ctx context.Context) {
+	now := time.Now()
+	present := map[string]bool{}
+	for _, inbox := range w.cfg.WatchDirs {
+		err := filepath.WalkDir(inbox, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				log.Printf("Watcher: %s: %v", p, err)
+				return nil
+			}
+			rel, _ := filepath.Rel(inbox, p)
+			if d.IsDir() {
+				if rel == watchDoneDir || rel == watchFailedDir || (p != inbox && strings.HasPrefix(d.Name(), ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || skipWatchedName(d.Name()) {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			present[p] = true
+			if !w.stable(p, info, now) {
+				return nil
+			}
+			w.process(ctx, inbox, rel)
+			delete(w.seen, p)
+			return ctx.Err()
+		})
+		if err != nil {
+			return
+		}
+	}
+	for p := range w.seen {
+		if !present[p] {
+			delete(w.seen, p)
+		}
+	}
+}
+
+// stable reports whether the file kept its size and mtime for WatchStableFor,
+// so that a copy still in progress is not picked up half written.
+func (w *Watcher) stable(p string, info fs.FileInfo, now time.Time) bool {
+	s, ok := w.seen[p]
+	if !ok || s.size != info.Size() || !s.mod.Equal(info.ModTime()) {
+		w.seen[p] = watchedFile{size: info.Size(), mod: info.ModTime(), since: now}
+		return false
+	}
+	return now.Sub(s.since) >= w.cfg.WatchStableFor
+}
+
+func (w *Watcher) process(ctx context.Context, inbox, rel string) {
+	src := filepath.Join(inbox, rel)
+	data, err := os.ReadFile(src)
+	if err != nil {
+		log.Printf("Watcher: reading %s: %v", src, err)
+		return
+	}
+	folder := filepath.ToSlash(filepath.Dir(rel))
+	if folder == "." {
+		folder = ""
+	}
+	sum := checksum(data)
+	entry := WatchLog{
+		File:        filepath.ToSlash(rel),
+		Folder:      folder,
+		ID:          uuid.NewSHA1(watchNamespace, []byte(filepath.ToSlash(rel)+"\x00"+sum)).String(),
+		SHA256:      sum,
+		ProcessedAt: time.Now(),
+	}
+	dest := watchDoneDir
+	if _, err := w.repo.Get(entry.ID); err == nil {
+		entry.Status = WatchAlreadyIngested
+	} else if _, err := w.ingest.SaveAndIndex(ctx, domain.Document{ID: entry.ID, Folder: folder, OriginalFilename: filepath.Base(rel)}, data); err != nil {
+		if ctx.Err() != nil {
+			return // shutting down; the file stays for the next run
+		}
+		entry.Status, entry.Error, dest = WatchFailed, err.Error(), watchFailedDir
+	} else {
+		entry.Status = WatchIngested
+	}
+	w.move(src, filepath.Join(inbox, dest, rel), entry)
+}
+
+// move writes the sidecar log and then moves the file next to it; an
+// earlier file of the same name is kept by suffixing the new one.
+func (w *Watcher) move(src, target string, entry WatchLog) {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		log.Printf("Watcher: %v", err)
+		return
+	}
+	if _, err := os.Stat(target); err == nil {
+		ext := filepath.Ext(target)
+		target = strings.TrimSuffix(target, ext) + "-" + entry.ProcessedAt.Format("20060102T150405") + ext
+	}
+	b, _ := json.MarshalIndent(entry, "", "  ")
+	if err := os.WriteFile(target+".log.json", b, 0644); err != nil {
+		log.Printf("Watcher: writing log for %s: %v", src, err)
+	}
+	if err := os.Rename(src, target); err != nil {
+		log.Printf("Watcher: moving %s: %v", src, err)
+		return
+	}
+	log.Printf("Watcher: %s %s -> %s", entry.File, entry.Status, target)
+}
+
+// skipWatchedName ignores hidden files and the temp names used by browsers
+// and office suites while a file is still being written.
+func skipWatchedName(name string) bool {
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "~$") {
+		return true
+	}
+	for _, suffix := range []string{".part", ".tmp", ".crdownload", ".partial"} {
+		if strings.HasSuffix(strings.ToLower(name), suffix) {
+			return true
+		}
+	}
+	return false
+}